@@ -0,0 +1,198 @@
+package failure
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// aggregatorSlots is the number of slots a window is divided into, counts
+// expire one slot at a time as the window slides.
+const aggregatorSlots = 10
+
+// Occurrences summarizes every failure seen by an Aggregator that shares a
+// fingerprint.
+type Occurrences struct {
+	Fingerprint string
+	// Err is the first failure seen with this fingerprint
+	Err error
+	// Count is the number of occurrences inside the window ending at the
+	// most recent failure seen by the aggregator
+	Count int
+	// Total is the number of occurrences since the last flush
+	Total     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+type bucket struct {
+	occ   Occurrences
+	slots [aggregatorSlots]int
+	index [aggregatorSlots]int64
+	// newest is the most recent slot an occurrence was added to
+	newest int64
+}
+
+func (b *bucket) add(slot int64) {
+	pos := slot % aggregatorSlots
+	switch {
+	case b.index[pos] > slot:
+		// the position already holds a newer slot, this one has expired
+		return
+	case b.index[pos] < slot:
+		b.index[pos] = slot
+		b.slots[pos] = 0
+	}
+	b.slots[pos]++
+
+	if slot > b.newest {
+		b.newest = slot
+	}
+}
+
+// count returns the occurrences in the window ending at slot
+func (b *bucket) count(slot int64) int {
+	var n int
+	for i := range b.slots {
+		if d := slot - b.index[i]; d >= 0 && d < aggregatorSlots {
+			n += b.slots[i]
+		}
+	}
+	return n
+}
+
+// Aggregator buckets failures by Fingerprint over a sliding window, turning
+// an error storm into a handful of summaries. Fingerprints that saw no
+// failure for a whole window are expired, so call Flush at least once per
+// window to report all of them. It is safe for concurrent use.
+type Aggregator struct {
+	mutex   sync.Mutex
+	window  time.Duration
+	last    time.Time
+	buckets map[string]*bucket
+	// swept is the last slot idle buckets were expired at
+	swept int64
+}
+
+// NewAggregator creates an Aggregator counting occurrences over window
+func NewAggregator(window time.Duration) *Aggregator {
+	if window < aggregatorSlots {
+		window = aggregatorSlots
+	}
+
+	return &Aggregator{
+		window:  window,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Add records an occurrence of e now. nil errors are ignored.
+func (a *Aggregator) Add(e error) {
+	a.AddAt(e, time.Now())
+}
+
+// AddAt records an occurrence of e at the given time, which is useful when
+// replaying failures read from a log. Occurrences older than the window
+// ending at the most recent failure seen are ignored.
+func (a *Aggregator) AddAt(e error, at time.Time) {
+	if e == nil {
+		return
+	}
+
+	fp := Fingerprint(e)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if at.After(a.last) {
+		a.last = at
+	}
+	now := a.slot(a.last)
+	slot := a.slot(at)
+	if now-slot >= aggregatorSlots {
+		return
+	}
+	if now > a.swept {
+		a.expire(now)
+	}
+
+	b, ok := a.buckets[fp]
+	if !ok {
+		b = &bucket{occ: Occurrences{Fingerprint: fp, Err: e, FirstSeen: at}}
+		a.buckets[fp] = b
+	}
+
+	b.add(slot)
+	b.occ.Total++
+	if at.Before(b.occ.FirstSeen) {
+		b.occ.FirstSeen = at
+	}
+	if at.After(b.occ.LastSeen) {
+		b.occ.LastSeen = at
+	}
+}
+
+// expire drops the buckets that saw nothing in the window ending at slot
+func (a *Aggregator) expire(slot int64) {
+	a.swept = slot
+	for fp, b := range a.buckets {
+		if slot-b.newest >= aggregatorSlots {
+			delete(a.buckets, fp)
+		}
+	}
+}
+
+// Occurrences returns a snapshot of every bucket, the most frequent first
+func (a *Aggregator) Occurrences() []Occurrences {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.snapshot()
+}
+
+// Flush returns a Multi with one failure per fingerprint, most frequent
+// first, each wrapping the first occurrence with its counts. The aggregator
+// is reset afterwards. nil is returned when nothing was recorded.
+func (a *Aggregator) Flush() *Multi {
+	a.mutex.Lock()
+	list := a.snapshot()
+	a.buckets = map[string]*bucket{}
+	a.mutex.Unlock()
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	var result *Multi
+	for _, o := range list {
+		result = Append(result, Wrap(o.Err, "%d occurrences between %s and %s",
+			o.Total, o.FirstSeen.Format(time.RFC3339), o.LastSeen.Format(time.RFC3339)))
+	}
+
+	return result
+}
+
+func (a *Aggregator) slot(at time.Time) int64 {
+	return at.UnixNano() / int64(a.window/aggregatorSlots)
+}
+
+func (a *Aggregator) snapshot() []Occurrences {
+	now := a.slot(a.last)
+	a.expire(now)
+
+	list := make([]Occurrences, 0, len(a.buckets))
+	for _, b := range a.buckets {
+		o := b.occ
+		o.Count = b.count(now)
+		list = append(list, o)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Total != list[j].Total {
+			return list[i].Total > list[j].Total
+		}
+		return list[i].Fingerprint < list[j].Fingerprint
+	})
+
+	return list
+}
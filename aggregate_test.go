@@ -0,0 +1,80 @@
+package failure_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregator(t *testing.T) {
+	agg := failure.NewAggregator(time.Minute)
+	start := time.Date(2022, 5, 26, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		agg.AddAt(failure.Timeout("call %d took too long", i), start.Add(time.Duration(i)*time.Second))
+	}
+	agg.AddAt(failure.NotFound("user 1 not found"), start.Add(10*time.Second))
+	agg.AddAt(nil, start)
+
+	list := agg.Occurrences()
+	require.Len(t, list, 2)
+
+	assert.Equal(t, 5, list[0].Total)
+	assert.Equal(t, 5, list[0].Count)
+	assert.True(t, failure.IsTimeout(list[0].Err))
+	assert.Equal(t, start, list[0].FirstSeen)
+	assert.Equal(t, start.Add(4*time.Second), list[0].LastSeen)
+	assert.Equal(t, 1, list[1].Total)
+
+	// sliding past the window expires the early timeouts
+	agg.AddAt(failure.NotFound("user 2 not found"), start.Add(63*time.Second))
+	list = agg.Occurrences()
+	require.Len(t, list, 1)
+	assert.Equal(t, 2, list[0].Count)
+	assert.Equal(t, 2, list[0].Total)
+
+	agg.AddAt(failure.Timeout("call 5 took too long"), start.Add(64*time.Second))
+	result := agg.Flush()
+	require.NotNil(t, result)
+	require.Len(t, result.Failures, 2)
+	assert.True(t, failure.IsNotFound(result.Failures[0]))
+	assert.Contains(t, result.Failures[0].Error(), "2 occurrences between")
+	assert.True(t, failure.IsTimeout(result.Failures[1]))
+	assert.Nil(t, agg.Flush())
+	assert.Empty(t, agg.Occurrences())
+}
+
+func TestAggregator_Add(t *testing.T) {
+	agg := failure.NewAggregator(time.Minute)
+	agg.Add(failure.System("boom"))
+	agg.Add(failure.System("boom"))
+
+	list := agg.Occurrences()
+	require.Len(t, list, 1)
+	assert.Equal(t, 2, list[0].Count)
+}
+
+func TestAggregator_LateAndIdle(t *testing.T) {
+	agg := failure.NewAggregator(time.Minute)
+	start := time.Date(2022, 5, 26, 12, 0, 0, 0, time.UTC)
+
+	agg.AddAt(failure.Timeout("slow"), start.Add(time.Minute))
+	// a late occurrence inside the window counts without resetting newer
+	// ones, one from before the window is ignored
+	agg.AddAt(failure.Timeout("slow"), start.Add(30*time.Second))
+	agg.AddAt(failure.Timeout("slow"), start)
+
+	list := agg.Occurrences()
+	require.Len(t, list, 1)
+	assert.Equal(t, 2, list[0].Count)
+	assert.Equal(t, 2, list[0].Total)
+
+	// fingerprints idle for a whole window are expired
+	agg.AddAt(failure.NotFound("user"), start.Add(3*time.Minute))
+	list = agg.Occurrences()
+	require.Len(t, list, 1)
+	assert.True(t, failure.IsNotFound(list[0].Err))
+}
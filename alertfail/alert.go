@@ -0,0 +1,230 @@
+// Package alertfail turns severe failures into compact alerts for Slack
+// incoming webhooks or generic alerting endpoints.
+package alertfail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/rsb/failure"
+)
+
+// Alert is the compact description of a failure sent to people
+type Alert struct {
+	Title       string            `json:"title"`
+	Severity    string            `json:"severity"`
+	Color       string            `json:"color"`
+	Category    string            `json:"category,omitempty"`
+	Fingerprint string            `json:"fingerprint"`
+	Message     string            `json:"message"`
+	Details     map[string]string `json:"details,omitempty"`
+	Origin      string            `json:"origin,omitempty"`
+	TraceURL    string            `json:"trace_url,omitempty"`
+}
+
+// Color returns the hex color used for alerts of severity s
+func Color(s failure.Severity) string {
+	switch s {
+	case failure.SeverityDebug:
+		return "#9e9e9e"
+	case failure.SeverityInfo:
+		return "#2196f3"
+	case failure.SeverityWarning:
+		return "#ff9800"
+	case failure.SeverityCritical:
+		return "#8b0000"
+	default:
+		return "#f44336"
+	}
+}
+
+// NewAlert describes err. The title is the category, or "failure" for
+// errors without one, followed by the fingerprint so repeated alerts are
+// easy to tell apart from new ones. traceURL may be empty.
+func NewAlert(err error, traceURL string) Alert {
+	severity := failure.SeverityOf(err)
+	a := Alert{
+		Title:       "failure",
+		Severity:    severity.String(),
+		Color:       Color(severity),
+		Fingerprint: failure.Fingerprint(err),
+		Message:     err.Error(),
+		TraceURL:    traceURL,
+	}
+
+	if c, ok := failure.Kind(err); ok {
+		a.Category = c.String()
+		a.Title = c.String()
+	}
+	a.Title += " " + a.Fingerprint
+
+	if details := failure.Details(err); len(details) > 0 {
+		a.Details = make(map[string]string, len(details))
+		for k, v := range details {
+			a.Details[k] = fmt.Sprint(v)
+		}
+	}
+
+	if frames, ok := failure.StackTrace(err); ok && len(frames) > 0 {
+		a.Origin = fmt.Sprintf("%s (%s:%d)", frames[0].Function, frames[0].File, frames[0].Line)
+	}
+
+	return a
+}
+
+// SlackField is a field of a Slack attachment
+type SlackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// SlackAttachment is a Slack message attachment
+type SlackAttachment struct {
+	Color     string       `json:"color"`
+	Title     string       `json:"title"`
+	TitleLink string       `json:"title_link,omitempty"`
+	Text      string       `json:"text"`
+	Fields    []SlackField `json:"fields,omitempty"`
+	Footer    string       `json:"footer,omitempty"`
+}
+
+// SlackMessage is the body of a Slack incoming webhook
+type SlackMessage struct {
+	Attachments []SlackAttachment `json:"attachments"`
+}
+
+// Slack formats a as a Slack incoming webhook message. The title links to
+// the trace, details become short fields sorted by name and the origin is
+// the footer.
+func Slack(a Alert) interface{} {
+	att := SlackAttachment{
+		Color:     a.Color,
+		Title:     a.Title,
+		TitleLink: a.TraceURL,
+		Text:      a.Message,
+		Footer:    a.Origin,
+		Fields:    []SlackField{{Title: "severity", Value: a.Severity, Short: true}},
+	}
+
+	keys := make([]string, 0, len(a.Details))
+	for k := range a.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		att.Fields = append(att.Fields, SlackField{Title: k, Value: a.Details[k], Short: true})
+	}
+
+	return SlackMessage{Attachments: []SlackAttachment{att}}
+}
+
+// Generic formats a as itself, for endpoints that accept the Alert JSON
+func Generic(a Alert) interface{} {
+	return a
+}
+
+// Webhook is a failure.Reporter posting alerts to a URL. Report blocks on
+// the request, wrap it in a failure.AsyncReporter to keep it off the hot
+// path:
+//
+//	failure.RegisterReporter(failure.NewAsyncReporter(&alertfail.Webhook{URL: url}, 64))
+//
+// Webhook URLs are credentials, they are never part of the errors passed to
+// OnError.
+type Webhook struct {
+	// URL receives the alerts
+	URL string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+	// Format builds the request body, it defaults to Slack
+	Format func(Alert) interface{}
+	// MinSeverity is the lowest severity alerted on, nil means
+	// failure.SeverityCritical:
+	//
+	//	min := failure.SeverityWarning
+	//	w := &alertfail.Webhook{URL: url, MinSeverity: &min}
+	MinSeverity *failure.Severity
+	// TraceURL returns the link to the trace of the request in ctx, it is
+	// optional
+	TraceURL func(ctx context.Context) string
+	// OnError is called when an alert could not be delivered, it is
+	// optional
+	OnError func(error)
+}
+
+// Report posts an alert for err when its severity is high enough
+func (w *Webhook) Report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	min := failure.SeverityCritical
+	if w.MinSeverity != nil {
+		min = *w.MinSeverity
+	}
+	if failure.SeverityOf(err) < min {
+		return
+	}
+
+	var trace string
+	if w.TraceURL != nil {
+		trace = w.TraceURL(ctx)
+	}
+
+	format := w.Format
+	if format == nil {
+		format = Slack
+	}
+
+	if e := w.post(ctx, format(NewAlert(err, trace))); e != nil && w.OnError != nil {
+		w.OnError(e)
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return failure.Wrap(err, "json.Marshal failed")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return failure.ToInvalidParam(withoutURL(err), "http.NewRequestWithContext failed")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return failure.WrapContext(ctx, withoutURL(err), "client.Do failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return failure.Server("webhook answered with status (%d)", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// withoutURL strips the request URL net/http puts in its errors, as the URL
+// of a webhook is a secret
+func withoutURL(err error) error {
+	var u *url.Error
+	if errors.As(err, &u) {
+		return fmt.Errorf("%s: %w", u.Op, u.Err)
+	}
+
+	return err
+}
@@ -0,0 +1,81 @@
+package alertfail_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/rsb/failure/alertfail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAlert(t *testing.T) {
+	err := failure.WithStack(failure.WithDetail(failure.Panic("nil map"), "order_id", 7))
+	a := alertfail.NewAlert(err, "https://trace/abc")
+
+	fp := failure.Fingerprint(err)
+	assert.Equal(t, "panic "+fp, a.Title)
+	assert.Equal(t, "critical", a.Severity)
+	assert.Equal(t, alertfail.Color(failure.SeverityCritical), a.Color)
+	assert.Equal(t, map[string]string{"order_id": "7"}, a.Details)
+	assert.Contains(t, a.Origin, "TestNewAlert")
+	assert.Equal(t, "https://trace/abc", a.TraceURL)
+
+	msg := alertfail.Slack(a).(alertfail.SlackMessage)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "https://trace/abc", msg.Attachments[0].TitleLink)
+	assert.Equal(t, []alertfail.SlackField{
+		{Title: "severity", Value: "critical", Short: true},
+		{Title: "order_id", Value: "7", Short: true},
+	}, msg.Attachments[0].Fields)
+}
+
+func TestWebhook(t *testing.T) {
+	var got []alertfail.SlackMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m alertfail.SlackMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		got = append(got, m)
+	}))
+	defer srv.Close()
+
+	w := &alertfail.Webhook{
+		URL:      srv.URL,
+		TraceURL: func(context.Context) string { return "https://trace/abc" },
+	}
+	var _ failure.Reporter = w
+
+	w.Report(context.Background(), failure.System("db down"))
+	w.Report(context.Background(), failure.Panic("nil map"))
+	w.Report(context.Background(), nil)
+
+	require.Len(t, got, 1)
+	assert.Contains(t, got[0].Attachments[0].Title, "panic")
+
+	var failed error
+	min := failure.SeverityError
+	bad := &alertfail.Webhook{
+		URL:         srv.URL + "/secret\x7f",
+		MinSeverity: &min,
+		OnError:     func(e error) { failed = e },
+	}
+	bad.Report(context.Background(), failure.System("db down"))
+	assert.True(t, failure.IsInvalidParam(failed))
+	assert.NotContains(t, failed.Error(), "secret")
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	bad.URL = closed.URL + "/secret"
+	bad.Report(context.Background(), failure.System("db down"))
+	require.Error(t, failed)
+	assert.NotContains(t, failed.Error(), "secret")
+
+	debug := failure.SeverityDebug
+	w.MinSeverity = &debug
+	w.Report(context.Background(), failure.WithSeverity(failure.System("noise"), failure.SeverityDebug))
+	assert.Len(t, got, 2)
+}
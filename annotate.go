@@ -0,0 +1,66 @@
+package failure
+
+// annotation attaches a piece of metadata to an error without changing its
+// message or what it matches with errors.Is and errors.As. Annotations are
+// never modified once created, enriching an error always returns a new
+// value wrapping the original.
+//
+// Metadata is deliberately stored as this chain of immutable values rather
+// than in a map shared by the wrappers: readers such as Details, SeverityOf
+// and LogAttrs only ever follow pointers that were fully built before they
+// were published, so they need no locks and can't race with middleware
+// enriching the same failure further up the stack.
+type annotation struct {
+	err   error
+	key   interface{}
+	value interface{}
+}
+
+func (a *annotation) Error() string {
+	return a.err.Error()
+}
+
+func (a *annotation) Unwrap() error {
+	return a.err
+}
+
+func annotate(e error, key, value interface{}) error {
+	if e == nil {
+		return nil
+	}
+
+	return &annotation{err: e, key: key, value: value}
+}
+
+// lookup returns the outermost value stored under key in the chain of e.
+// The search stops at a Multi, metadata of its members does not describe
+// the Multi as a whole.
+func lookup(e error, key interface{}) (interface{}, bool) {
+	for e != nil {
+		switch x := e.(type) {
+		case *annotation:
+			if x.key == key {
+				return x.value, true
+			}
+		case *Multi:
+			return nil, false
+		}
+
+		e = next(e)
+	}
+
+	return nil, false
+}
+
+// next returns the error e wraps when it wraps a single one. A classified
+// error is followed to the error that was reclassified.
+func next(e error) error {
+	switch x := e.(type) {
+	case *classified:
+		return x.err
+	case interface{ Unwrap() error }:
+		return x.Unwrap()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package failure_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+var errSentinel = failure.NotFound("sentinel")
+
+func TestMetadata_CopyOnWrite(t *testing.T) {
+	base := failure.WithDetail(errSentinel, "shared", true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := failure.WithDetail(base, "worker", i)
+			err = failure.WithSeverity(err, failure.Severity(i%5))
+			if i%2 == 0 {
+				err = failure.MarkRetryable(err)
+			}
+
+			assert.Equal(t, map[string]interface{}{"shared": true, "worker": i}, failure.Details(err))
+			assert.Equal(t, failure.Severity(i%5), failure.SeverityOf(err))
+			assert.Equal(t, i%2 == 0, failure.IsRetryable(err))
+			assert.True(t, failure.IsNotFound(err))
+			assert.Equal(t, base.Error(), err.Error())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]interface{}{"shared": true}, failure.Details(base))
+	assert.Empty(t, failure.Details(errSentinel))
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(errSentinel))
+	assert.False(t, failure.IsRetryable(errSentinel))
+}
+
+func TestMetadata_OverrideKeepsOriginal(t *testing.T) {
+	first := failure.WithDetail(errSentinel, "id", 1)
+	second := failure.WithDetail(first, "id", 2)
+
+	v, _ := failure.Detail(first, "id")
+	assert.Equal(t, 1, v)
+	v, _ = failure.Detail(second, "id")
+	assert.Equal(t, 2, v)
+	assert.Equal(t, fmt.Sprint(errSentinel), fmt.Sprint(second))
+}
+
+func TestMetadata_ConcurrentReadsDuringEnrichment(t *testing.T) {
+	// a failure handed to several middleware layers at once, each enriching
+	// it while a logger reads whatever the previous layer produced
+	errs := make(chan error, 64)
+	root := failure.WithStack(failure.Timeout("upstream"))
+
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for err := range errs {
+				_ = failure.Details(err)
+				_ = failure.SeverityOf(err)
+				_ = failure.LogAttrs(err)
+				_ = failure.Fingerprint(err)
+				_, _ = failure.StackTrace(err)
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		w := w
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			err := root
+			for layer := 0; layer < 20; layer++ {
+				err = failure.WithDetail(err, fmt.Sprintf("layer%d", layer), w)
+				errs <- err
+			}
+			assert.Len(t, failure.Details(err), 20)
+		}()
+	}
+
+	writers.Wait()
+	close(errs)
+	readers.Wait()
+
+	assert.Empty(t, failure.Details(root))
+}
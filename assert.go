@@ -0,0 +1,63 @@
+package failure
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+var assertPanics atomic.Bool
+
+// SetAssertPanics makes Assert and AssertNotNil panic with their failure
+// instead of returning it, e.g. in tests and development builds where a
+// broken invariant should stop everything. They return it by default.
+func SetAssertPanics(on bool) {
+	assertPanics.Store(on)
+}
+
+// Assert guards an internal invariant. When cond is false it returns an
+// InvalidState failure with the message, carrying the stack trace of the
+// caller, see StackTrace. nil is returned when cond holds.
+//
+//	if err := failure.Assert(len(ids) == len(rows), "got %d rows for %d ids", len(rows), len(ids)); err != nil {
+//		return err
+//	}
+func Assert(cond bool, format string, a ...interface{}) error {
+	if cond {
+		return nil
+	}
+
+	return assertion(annotate(InvalidState(format, a...), stackKey{}, callers(3)))
+}
+
+// AssertNotNil is Assert for the invariant that v, named name in the
+// message, is not nil. Typed nil pointers, maps, slices, channels and
+// functions count as nil.
+func AssertNotNil(v interface{}, name string) error {
+	if !isNil(v) {
+		return nil
+	}
+
+	return assertion(annotate(InvalidState("%s is nil", name), stackKey{}, callers(3)))
+}
+
+// assertion returns err, or panics with it when SetAssertPanics is on
+func assertion(err error) error {
+	if assertPanics.Load() {
+		panic(err)
+	}
+
+	return err
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return false
+}
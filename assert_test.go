@@ -0,0 +1,41 @@
+package failure_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssert(t *testing.T) {
+	assert.NoError(t, failure.Assert(true, "never"))
+
+	err := failure.Assert(1 > 2, "got %d rows", 3)
+	require.Error(t, err)
+	assert.True(t, failure.IsInvalidState(err))
+	assert.Equal(t, "got 3 rows: "+failure.InvalidStateMsg, err.Error())
+
+	frames, ok := failure.StackTrace(err)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(frames[0].Function, "TestAssert"))
+}
+
+func TestAssertNotNil(t *testing.T) {
+	var user *struct{}
+	var m map[string]int
+
+	assert.NoError(t, failure.AssertNotNil(&struct{}{}, "user"))
+	assert.NoError(t, failure.AssertNotNil(0, "count"))
+	assert.Error(t, failure.AssertNotNil(nil, "user"))
+	assert.Error(t, failure.AssertNotNil(m, "index"))
+
+	err := failure.AssertNotNil(user, "user")
+	assert.True(t, failure.IsInvalidState(err))
+	assert.Equal(t, "user is nil: "+failure.InvalidStateMsg, err.Error())
+
+	failure.SetAssertPanics(true)
+	defer failure.SetAssertPanics(false)
+	assert.Panics(t, func() { _ = failure.AssertNotNil(user, "user") })
+}
@@ -0,0 +1,104 @@
+package failure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// awsCodes maps the error codes of AWS services to categories
+var awsCodes = map[string]Category{
+	"ConditionalCheckFailedException":        KindConflict,
+	"TransactionConflictException":           KindConflict,
+	"OptimisticLockException":                KindConflict,
+	"ResourceNotFoundException":              KindNotFound,
+	"NoSuchKey":                              KindNotFound,
+	"NoSuchBucket":                           KindNotFound,
+	"NotFound":                               KindNotFound,
+	"ResourceInUseException":                 KindAlreadyExists,
+	"ResourceAlreadyExistsException":         KindAlreadyExists,
+	"EntityAlreadyExists":                    KindAlreadyExists,
+	"BucketAlreadyExists":                    KindAlreadyExists,
+	"BucketAlreadyOwnedByYou":                KindAlreadyExists,
+	"ThrottlingException":                    KindRateLimited,
+	"Throttling":                             KindRateLimited,
+	"TooManyRequestsException":               KindRateLimited,
+	"ProvisionedThroughputExceededException": KindRateLimited,
+	"RequestLimitExceeded":                   KindRateLimited,
+	"SlowDown":                               KindRateLimited,
+	"LimitExceededException":                 KindResourceExhausted,
+	"ServiceQuotaExceededException":          KindResourceExhausted,
+	"AccessDenied":                           KindNotAuthorized,
+	"AccessDeniedException":                  KindNotAuthorized,
+	"UnauthorizedOperation":                  KindNotAuthorized,
+	"UnrecognizedClientException":            KindNotAuthenticated,
+	"InvalidClientTokenId":                   KindNotAuthenticated,
+	"ExpiredToken":                           KindNotAuthenticated,
+	"ExpiredTokenException":                  KindNotAuthenticated,
+	"ValidationException":                    KindValidation,
+	"ValidationError":                        KindValidation,
+	"InvalidParameterValue":                  KindValidation,
+	"RequestTimeout":                         KindTimeout,
+	"RequestTimeoutException":                KindTimeout,
+	"ServiceUnavailable":                     KindUnavailable,
+	"ServiceUnavailableException":            KindUnavailable,
+	"InternalFailure":                        KindUnavailable,
+	"InternalServerError":                    KindUnavailable,
+}
+
+// awsStatuses maps the HTTP status of AWS responses with an unknown error
+// code to categories
+var awsStatuses = map[int]Category{
+	http.StatusBadRequest:          KindBadRequest,
+	http.StatusUnauthorized:        KindNotAuthenticated,
+	http.StatusForbidden:           KindNotAuthorized,
+	http.StatusNotFound:            KindNotFound,
+	http.StatusConflict:            KindConflict,
+	http.StatusTooManyRequests:     KindRateLimited,
+	http.StatusInternalServerError: KindUnavailable,
+	http.StatusBadGateway:          KindUnavailable,
+	http.StatusServiceUnavailable:  KindUnavailable,
+	http.StatusGatewayTimeout:      KindTimeout,
+}
+
+// FromAWS classifies an error returned by the AWS SDK for Go v2 from its
+// error code, ResourceNotFoundException is NotFound,
+// ConditionalCheckFailedException is Conflict, ThrottlingException is
+// RateLimited, AccessDenied is NotAuthorized and so on, or else from the
+// HTTP status of the response. Canceled and timed out calls are Canceled
+// and Timeout. The original error stays reachable with errors.As, so
+// the smithy.APIError can still be inspected, and errors that can't be
+// classified are returned unchanged.
+//
+//	out, err := client.PutItem(ctx, in)
+//	if err != nil {
+//		return failure.Wrap(failure.FromAWS(err), "put order %s", id)
+//	}
+//
+// The package does not import the SDK, errors are recognized by the
+// ErrorCode method of smithy.APIError and the HTTPStatusCode method of the
+// response errors.
+func FromAWS(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return FromContextErr(err)
+	}
+
+	var api interface{ ErrorCode() string }
+	if errors.As(err, &api) {
+		if c, ok := awsCodes[api.ErrorCode()]; ok {
+			return &classified{err: err, kind: c}
+		}
+	}
+
+	var resp interface{ HTTPStatusCode() int }
+	if errors.As(err, &resp) {
+		if c, ok := awsStatuses[resp.HTTPStatusCode()]; ok {
+			return &classified{err: err, kind: c}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,67 @@
+package failure_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiError mimics smithy.GenericAPIError
+type apiError struct {
+	Code string
+}
+
+func (e *apiError) Error() string     { return "api error " + e.Code }
+func (e *apiError) ErrorCode() string { return e.Code }
+
+// responseError mimics awshttp.ResponseError
+type responseError struct {
+	Status int
+	Err    error
+}
+
+func (e *responseError) Error() string       { return fmt.Sprintf("status %d: %v", e.Status, e.Err) }
+func (e *responseError) Unwrap() error       { return e.Err }
+func (e *responseError) HTTPStatusCode() int { return e.Status }
+
+func TestFromAWS(t *testing.T) {
+	wrap := func(status int, code string) error {
+		return fmt.Errorf("operation error DynamoDB: PutItem, %w", &responseError{Status: status, Err: &apiError{Code: code}})
+	}
+
+	tests := []struct {
+		err  error
+		kind failure.Category
+	}{
+		{err: wrap(http.StatusBadRequest, "ConditionalCheckFailedException"), kind: failure.KindConflict},
+		{err: wrap(http.StatusBadRequest, "ResourceNotFoundException"), kind: failure.KindNotFound},
+		{err: wrap(http.StatusBadRequest, "ThrottlingException"), kind: failure.KindRateLimited},
+		{err: wrap(http.StatusForbidden, "AccessDenied"), kind: failure.KindNotAuthorized},
+		{err: wrap(http.StatusNotFound, "SomethingNew"), kind: failure.KindNotFound},
+		{err: wrap(http.StatusServiceUnavailable, "SomethingNew"), kind: failure.KindUnavailable},
+		{err: fmt.Errorf("operation error S3: GetObject, %w", context.DeadlineExceeded), kind: failure.KindTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			err := failure.FromAWS(tt.err)
+			kind, ok := failure.Kind(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.kind, kind)
+			assert.True(t, errors.Is(err, tt.err))
+		})
+	}
+
+	var api *apiError
+	assert.True(t, errors.As(failure.FromAWS(wrap(http.StatusBadRequest, "ThrottlingException")), &api))
+
+	unknown := wrap(http.StatusTeapot, "SomethingNew")
+	assert.Equal(t, unknown, failure.FromAWS(unknown))
+	assert.NoError(t, failure.FromAWS(nil))
+}
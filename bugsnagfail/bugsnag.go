@@ -0,0 +1,123 @@
+// Package bugsnagfail reports failures to Bugsnag.
+//
+// The package does not import the Bugsnag SDK. The Reporter builds an Event
+// and hands it to a Notify function, which converts it into a call to
+// bugsnag.Notify:
+//
+//	r := bugsnagfail.NewReporter(func(e bugsnagfail.Event) error {
+//		severity := bugsnag.SeverityError
+//		switch e.Severity {
+//		case "warning":
+//			severity = bugsnag.SeverityWarning
+//		case "info":
+//			severity = bugsnag.SeverityInfo
+//		}
+//
+//		return bugsnag.Notify(e.Err, e.Context, severity,
+//			bugsnag.ErrorClass{Name: e.ErrorClass},
+//			bugsnag.MetaData(e.MetaData),
+//			bugsnag.HandledState{
+//				SeverityReason:   bugsnag.SeverityReasonHandledError,
+//				OriginalSeverity: severity,
+//				Unhandled:        e.Unhandled,
+//			})
+//	})
+package bugsnagfail
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rsb/failure"
+)
+
+const (
+	// FailureTab is the metadata tab holding the classification of a failure
+	FailureTab = "failure"
+	// DetailsTab is the metadata tab holding the details of a failure
+	DetailsTab = "details"
+)
+
+// Event is everything the Reporter knows about a failure, expressed in
+// Bugsnag terms.
+type Event struct {
+	Context    context.Context
+	Err        error
+	ErrorClass string
+	// Severity is one of error, warning or info
+	Severity  string
+	Unhandled bool
+	MetaData  map[string]map[string]interface{}
+}
+
+// NotifyFunc sends an Event to Bugsnag
+type NotifyFunc func(Event) error
+
+// Reporter is a failure.Reporter sending failures to Bugsnag
+type Reporter struct {
+	notify NotifyFunc
+}
+
+// NewReporter creates a Reporter sending events with notify
+func NewReporter(notify NotifyFunc) *Reporter {
+	return &Reporter{notify: notify}
+}
+
+// Report converts err into an Event and sends it. Errors returned by the
+// notify function are dropped, there is nowhere left to report them.
+func (r *Reporter) Report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	_ = r.notify(NewEvent(ctx, err))
+}
+
+// NewEvent converts err into an Event. The category becomes the error
+// class and the Bugsnag severity follows its log level, see
+// failure.LevelFor. Failures logged as critical that are not retryable are
+// reported as unhandled, everything else as handled.
+func NewEvent(ctx context.Context, err error) Event {
+	sev := failure.SeverityOf(err)
+	level := failure.LevelFor(err)
+	retryable := failure.IsRetryable(err)
+
+	class := "error"
+	tab := map[string]interface{}{
+		"severity":    sev.String(),
+		"retryable":   retryable,
+		"fingerprint": failure.Fingerprint(err),
+	}
+	if c, ok := failure.Kind(err); ok {
+		class = c.String()
+		tab["category"] = class
+	}
+
+	meta := map[string]map[string]interface{}{
+		FailureTab: tab,
+	}
+	if details := failure.Details(err); len(details) > 0 {
+		meta[DetailsTab] = details
+	}
+
+	return Event{
+		Context:    ctx,
+		Err:        err,
+		ErrorClass: class,
+		Severity:   Severity(level),
+		Unhandled:  level >= failure.LevelCritical && !retryable,
+		MetaData:   meta,
+	}
+}
+
+// Severity maps a log level onto one of the three Bugsnag severities
+func Severity(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warning"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,62 @@
+package bugsnagfail_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/rsb/failure/bugsnagfail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter(t *testing.T) {
+	var events []bugsnagfail.Event
+	r := bugsnagfail.NewReporter(func(e bugsnagfail.Event) error {
+		events = append(events, e)
+		return errors.New("ignored")
+	})
+
+	err := failure.WithDetail(failure.Panic("nil map"), "order_id", "o-1")
+	r.Report(context.Background(), err)
+	r.Report(context.Background(), nil)
+
+	require.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, "panic", e.ErrorClass)
+	assert.Equal(t, "error", e.Severity)
+	assert.True(t, e.Unhandled)
+	assert.Equal(t, "panic", e.MetaData[bugsnagfail.FailureTab]["category"])
+	assert.Equal(t, "critical", e.MetaData[bugsnagfail.FailureTab]["severity"])
+	assert.Equal(t, "o-1", e.MetaData[bugsnagfail.DetailsTab]["order_id"])
+
+	var _ failure.Reporter = r
+}
+
+func TestNewEvent(t *testing.T) {
+	ctx := context.Background()
+
+	e := bugsnagfail.NewEvent(ctx, failure.Timeout("slow"))
+	assert.Equal(t, "timeout", e.ErrorClass)
+	assert.False(t, e.Unhandled)
+	assert.Equal(t, true, e.MetaData[bugsnagfail.FailureTab]["retryable"])
+	assert.NotContains(t, e.MetaData, bugsnagfail.DetailsTab)
+
+	e = bugsnagfail.NewEvent(ctx, failure.MarkRetryable(failure.Startup("no db")))
+	assert.False(t, e.Unhandled)
+
+	e = bugsnagfail.NewEvent(ctx, errors.New("foo"))
+	assert.Equal(t, "error", e.ErrorClass)
+	assert.NotContains(t, e.MetaData[bugsnagfail.FailureTab], "category")
+
+	e = bugsnagfail.NewEvent(ctx, failure.NotFound("user"))
+	assert.Equal(t, "warning", e.Severity)
+	assert.Equal(t, "info", bugsnagfail.Severity(slog.LevelDebug))
+
+	defer failure.SetCategoryLevels(nil)
+	failure.SetCategoryLevels(map[failure.Category]slog.Level{failure.KindNotFound: slog.LevelInfo})
+	e = bugsnagfail.NewEvent(ctx, failure.NotFound("user"))
+	assert.Equal(t, "info", e.Severity)
+}
@@ -0,0 +1,79 @@
+package failure
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// Detail keys set by WithBuildInfo
+const (
+	BuildVersionDetail  = "build.version"
+	BuildRevisionDetail = "build.revision"
+	BuildModifiedDetail = "build.modified"
+)
+
+// BuildInfo identifies the build of the running binary
+type BuildInfo struct {
+	// Version is the version of the main module, "(devel)" for local builds
+	Version string
+	// Revision is the VCS revision the binary was built from
+	Revision string
+	// Modified reports whether the working tree had uncommitted changes
+	Modified bool
+}
+
+var buildInfo struct {
+	once sync.Once
+	info BuildInfo
+	ok   bool
+}
+
+// ReadBuildInfo returns the build information embedded in the binary by
+// the go command. It is only read once.
+func ReadBuildInfo() (BuildInfo, bool) {
+	buildInfo.once.Do(func() {
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+
+		buildInfo.ok = true
+		buildInfo.info.Version = bi.Main.Version
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				buildInfo.info.Revision = s.Value
+			case "vcs.modified":
+				buildInfo.info.Modified = s.Value == "true"
+			}
+		}
+	})
+
+	return buildInfo.info, buildInfo.ok
+}
+
+// WithBuildInfo returns e carrying the version, VCS revision and dirty flag
+// of the running binary as details, so reports say exactly which build
+// produced them. e is returned as is when the binary has no build info.
+func WithBuildInfo(e error) error {
+	info, ok := ReadBuildInfo()
+	if e == nil || !ok {
+		return e
+	}
+
+	e = WithDetail(e, BuildVersionDetail, info.Version)
+	if info.Revision != "" {
+		e = WithDetail(e, BuildRevisionDetail, info.Revision)
+		e = WithDetail(e, BuildModifiedDetail, info.Modified)
+	}
+
+	return e
+}
+
+// SetStampBuildInfo makes Report add the build info to every failure it
+// sends, see WithBuildInfo. It is off by default.
+func SetStampBuildInfo(on bool) {
+	reporting.Lock()
+	defer reporting.Unlock()
+	reporting.stampBuild = on
+}
@@ -0,0 +1,45 @@
+package failure_test
+
+import (
+	"context"
+	"runtime/debug"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBuildInfo(t *testing.T) {
+	bi, ok := debug.ReadBuildInfo()
+	require.True(t, ok)
+
+	info, ok := failure.ReadBuildInfo()
+	require.True(t, ok)
+	assert.Equal(t, bi.Main.Version, info.Version)
+
+	err := failure.WithBuildInfo(failure.System("boom"))
+	v, ok := failure.Detail(err, failure.BuildVersionDetail)
+	assert.True(t, ok)
+	assert.Equal(t, info.Version, v)
+	assert.True(t, failure.IsSystem(err))
+
+	assert.Nil(t, failure.WithBuildInfo(nil))
+}
+
+func TestSetStampBuildInfo(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	failure.SetStampBuildInfo(true)
+	failure.Report(context.Background(), failure.System("boom"))
+	failure.SetStampBuildInfo(false)
+	failure.Report(context.Background(), failure.System("boom"))
+
+	reported := rec.reported()
+	require.Len(t, reported, 2)
+	_, ok := failure.Detail(reported[0], failure.BuildVersionDetail)
+	assert.True(t, ok)
+	_, ok = failure.Detail(reported[1], failure.BuildVersionDetail)
+	assert.False(t, ok)
+}
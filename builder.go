@@ -0,0 +1,98 @@
+package failure
+
+import (
+	"errors"
+	"sort"
+)
+
+// E builds a fully annotated failure in one call from any combination of:
+//
+//	Category                the category of the failure
+//	Operation               the operation that failed
+//	error                   the error being wrapped
+//	string                  the message
+//	Severity                overrides the severity of the category
+//	map[string]interface{}  details, see WithDetail
+//
+// For example
+//
+//	failure.E(failure.Operation("orders.Create"), failure.KindNotFound, err,
+//		"customer is missing", map[string]interface{}{"customer_id": id})
+//
+// When both a category and an error are given the error is classified as
+// the category and stays reachable with errors.Is. When an argument kind is
+// repeated the last one wins. An argument of any other type produces a
+// System failure describing the bad call. nil is returned when there are no
+// arguments.
+func E(args ...interface{}) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	var (
+		cat         Category
+		op          Operation
+		err         error
+		msg         string
+		severity    Severity
+		hasSeverity bool
+		details     map[string]interface{}
+	)
+
+	for _, arg := range args {
+		switch x := arg.(type) {
+		case nil:
+		case Category:
+			cat = x
+		case Operation:
+			op = x
+		case string:
+			msg = x
+		case Severity:
+			severity, hasSeverity = x, true
+		case map[string]interface{}:
+			details = x
+		case error:
+			err = x
+		default:
+			return Wrap(KindSystem, "failure.E: unknown argument type %T (%v)", arg, arg)
+		}
+	}
+
+	var out error
+	switch {
+	case cat != "" && err != nil && msg != "":
+		out = classify(err, cat, "%s", msg)
+	case cat != "" && err != nil:
+		out = &classified{err: err, kind: cat}
+	case cat != "" && msg != "":
+		out = Wrap(cat, "%s", msg)
+	case cat != "":
+		out = cat
+	case err != nil && msg != "":
+		out = Wrap(err, "%s", msg)
+	case err != nil:
+		out = err
+	default:
+		out = errors.New(msg)
+	}
+
+	if op != "" {
+		out = &operation{op: op, err: out}
+	}
+
+	if hasSeverity {
+		out = WithSeverity(out, severity)
+	}
+
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out = WithDetail(out, k, details[k])
+	}
+
+	return out
+}
@@ -0,0 +1,61 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE(t *testing.T) {
+	db := errors.New("no rows")
+	err := failure.E(failure.Operation("orders.Create"), failure.KindNotFound, db,
+		"customer is missing", failure.SeverityInfo, map[string]interface{}{"customer_id": 7})
+
+	assert.Equal(t, "orders.Create: customer is missing: no rows: "+failure.NotFoundMsg, err.Error())
+	assert.True(t, failure.IsNotFound(err))
+	assert.True(t, errors.Is(err, db))
+	assert.Equal(t, failure.SeverityInfo, failure.SeverityOf(err))
+
+	id, ok := failure.Detail(err, "customer_id")
+	require.True(t, ok)
+	assert.Equal(t, 7, id)
+}
+
+func TestE_Partial(t *testing.T) {
+	assert.Nil(t, failure.E())
+
+	err := failure.E(failure.KindTimeout, "call billing")
+	assert.True(t, failure.IsTimeout(err))
+	assert.Equal(t, failure.Timeout("call billing").Error(), err.Error())
+
+	err = failure.E(failure.KindValidation)
+	assert.True(t, failure.IsValidation(err))
+	assert.Equal(t, failure.ValidationMsg, err.Error())
+
+	db := errors.New("no rows")
+	err = failure.E(db, "load")
+	assert.Equal(t, "load: no rows", err.Error())
+	_, ok := failure.Kind(err)
+	assert.False(t, ok)
+
+	err = failure.E(failure.Operation("db.Insert"), db)
+	assert.Equal(t, "db.Insert: no rows", err.Error())
+	assert.True(t, errors.Is(err, db))
+
+	err = failure.E(failure.KindConfig, db)
+	assert.True(t, failure.IsConfig(err))
+	assert.Equal(t, "no rows: "+failure.ConfigMsg, err.Error())
+
+	assert.Equal(t, "plain", failure.E("plain").Error())
+	assert.Equal(t, "100% done: no rows", failure.E(db, "100% done").Error())
+	assert.Equal(t, "100% done: "+failure.TimeoutMsg, failure.E(failure.KindTimeout, "100% done").Error())
+}
+
+func TestE_UnknownArgument(t *testing.T) {
+	err := failure.E(failure.KindNotFound, 42)
+	assert.True(t, failure.IsSystem(err))
+	assert.Contains(t, err.Error(), "unknown argument type int (42)")
+}
@@ -0,0 +1,486 @@
+package failure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Field is the validation failure of a single input field
+type Field struct {
+	Name string
+	Msg  string
+	// key and args translate Msg, see Catalog.AddFieldKey
+	key  string
+	args []interface{}
+}
+
+// FieldGroup holds the failed fields of one part of the input, such as the
+// body, the query string or a nested object.
+type FieldGroup struct {
+	Name   string
+	Fields map[string]*Field
+	order  []string
+}
+
+// Catalog accumulates field failures while validating a request so they can
+// all be returned at once. It is an error classified as InvalidAPIFields
+// and is written to clients with its fields grouped, see WriteCatalog.
+type Catalog struct {
+	// Key identifies what was validated, such as "create_user"
+	Key string
+	// Status is the HTTP response status, it defaults to 422
+	Status int
+	Groups map[string]*FieldGroup
+	order  []string
+}
+
+// NewCatalog creates an empty Catalog, a zero status means 422
+func NewCatalog(key string, status int) *Catalog {
+	if status == 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	return &Catalog{
+		Key:    key,
+		Status: status,
+		Groups: map[string]*FieldGroup{},
+	}
+}
+
+// AddField records that field of group failed with the message. A second
+// failure of the same field replaces the first and keeps its position,
+// groups and fields are listed in the order they were first added.
+func (c *Catalog) AddField(group, field, msg string, a ...interface{}) {
+	c.setField(group, &Field{Name: field, Msg: fmt.Sprintf(msg, a...)})
+}
+
+func (c *Catalog) setField(group string, f *Field) {
+	if c.Groups == nil {
+		c.Groups = map[string]*FieldGroup{}
+	}
+
+	g, ok := c.Groups[group]
+	if !ok {
+		g = &FieldGroup{Name: group, Fields: map[string]*Field{}}
+		c.Groups[group] = g
+		c.order = append(c.order, group)
+	}
+	if g.Fields == nil {
+		g.Fields = map[string]*Field{}
+	}
+
+	if _, ok := g.Fields[f.Name]; !ok {
+		g.order = append(g.order, f.Name)
+	}
+	g.Fields[f.Name] = f
+}
+
+// Add records e as the failure of field, nil errors are ignored. The
+// message of a RestAPI is used when it has one.
+func (c *Catalog) Add(group, field string, e error) {
+	if e == nil {
+		return
+	}
+
+	msg := e.Error()
+	var r *RestAPI
+	if errors.As(e, &r) && r.Msg != "" {
+		msg = r.Msg
+	}
+
+	c.AddField(group, field, "%s", msg)
+}
+
+// Check records msg as the failure of field when cond doesn't hold. It
+// returns cond so dependent checks can be skipped:
+//
+//	c := failure.NewCatalog("create_user", 0)
+//	if c.Check(in.Email != "", "body", "email", "is required") {
+//		c.Check(strings.Contains(in.Email, "@"), "body", "email", "must contain an @")
+//	}
+//	c.Check(in.Age >= 18, "body", "age", "must be at least 18")
+//	return c.ErrorOrNil()
+func (c *Catalog) Check(cond bool, group, field, msg string) bool {
+	if !cond {
+		c.AddField(group, field, "%s", msg)
+	}
+
+	return cond
+}
+
+// Ensure runs fn and records its failure as the failure of field. It
+// returns whether fn succeeded.
+func (c *Catalog) Ensure(fn func() error, group, field string) bool {
+	err := fn()
+	c.Add(group, field, err)
+
+	return err == nil
+}
+
+// ErrorCount returns the number of failed fields
+func (c *Catalog) ErrorCount() int {
+	if c == nil {
+		return 0
+	}
+
+	var n int
+	for _, g := range c.Groups {
+		n += len(g.Fields)
+	}
+	return n
+}
+
+// AllFailures returns the messages of the failed fields by group and field.
+// Maps have no order, Error and MarshalJSON list the fields in the order
+// they were added.
+func (c *Catalog) AllFailures() map[string]map[string]string {
+	out := map[string]map[string]string{}
+	if c == nil {
+		return out
+	}
+
+	for name, g := range c.Groups {
+		if len(g.Fields) == 0 {
+			continue
+		}
+		fields := make(map[string]string, len(g.Fields))
+		for k, f := range g.Fields {
+			fields[k] = f.Msg
+		}
+		out[name] = fields
+	}
+
+	return out
+}
+
+// MarshalJSON writes the catalog as its key, status and the messages of the
+// failed fields by group and field, the same schema as the "key" and
+// "errors" of its ErrorResponse:
+//
+//	{"key":"create_user","status":422,"errors":{"body":{"email":"is required"}}}
+//
+// Every member is always present so clients can rely on the shape.
+func (c *Catalog) MarshalJSON() ([]byte, error) {
+	x := encodedCatalog{Errors: c.fieldsByGroup()}
+	if c != nil {
+		x.Key = c.Key
+		x.Status = c.Status
+	}
+
+	return json.Marshal(x)
+}
+
+// UnmarshalJSON replaces the key, status and fields of c with the decoded
+// ones, a missing status means 422.
+func (c *Catalog) UnmarshalJSON(data []byte) error {
+	var x encodedCatalog
+	if err := json.Unmarshal(data, &x); err != nil {
+		return Wrap(err, "json.Unmarshal failed")
+	}
+
+	x.decode(c)
+	return nil
+}
+
+// ErrorOrNil returns the catalog as an error when a field failed, nil
+// otherwise.
+func (c *Catalog) ErrorOrNil() error {
+	if c.ErrorCount() == 0 {
+		return nil
+	}
+
+	return c
+}
+
+// Error lists the failed fields as "group.field: msg" in the order they
+// were added.
+func (c *Catalog) Error() string {
+	var lines []string
+	for _, g := range c.fieldsByGroup() {
+		for _, f := range g.fields {
+			lines = append(lines, fieldPath(g.name, f.Name)+": "+f.Msg)
+		}
+	}
+
+	msg := fmt.Sprintf("%d invalid fields", len(lines))
+	if c.Key != "" {
+		msg = c.Key + ": " + msg
+	}
+	if len(lines) > 0 {
+		msg += " (" + strings.Join(lines, ", ") + ")"
+	}
+
+	return msg + ": " + KindInvalidAPIFields.Error()
+}
+
+// fieldPath joins a group and a field, fields of the unnamed group are
+// left as is.
+func fieldPath(group, field string) string {
+	if group == "" {
+		return field
+	}
+	return group + "." + field
+}
+
+// ValidateSlice runs fn on every item and records each failure in the
+// unnamed group under the index of the item, "items[3]", so bulk endpoints
+// report exactly which rows failed. When fn returns a Catalog its fields
+// are recorded one by one, "items[3].body.email". The catalog is always
+// returned, use ErrorOrNil to check for failures.
+func ValidateSlice[T any](items []T, fn func(T) error) *Catalog {
+	c := NewCatalog("", 0)
+	for i, item := range items {
+		err := fn(item)
+		if err == nil {
+			continue
+		}
+
+		field := "items[" + strconv.Itoa(i) + "]"
+
+		var nested *Catalog
+		if errors.As(err, &nested) && nested.ErrorCount() > 0 {
+			for _, g := range nested.fieldsByGroup() {
+				for j := range g.fields {
+					f := g.fields[j]
+					f.Name = field + "." + fieldPath(g.name, f.Name)
+					c.setField("", &f)
+				}
+			}
+			continue
+		}
+
+		c.Add("", field, err)
+	}
+
+	return c
+}
+
+// Unwrap classifies the catalog as InvalidAPIFields
+func (c *Catalog) Unwrap() error {
+	return KindInvalidAPIFields
+}
+
+// SafeCatalog accumulates field failures from many goroutines, such as
+// field checks fanned out while validating a request. It is safe for
+// concurrent use, unlike Catalog.
+type SafeCatalog struct {
+	mutex sync.Mutex
+	c     *Catalog
+}
+
+// NewSafeCatalog creates an empty SafeCatalog, see NewCatalog
+func NewSafeCatalog(key string, status int) *SafeCatalog {
+	return &SafeCatalog{c: NewCatalog(key, status)}
+}
+
+// AddField records a failed field, see Catalog.AddField
+func (s *SafeCatalog) AddField(group, field, msg string, a ...interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.c.AddField(group, field, msg, a...)
+}
+
+// Add records e as the failure of field, see Catalog.Add
+func (s *SafeCatalog) Add(group, field string, e error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.c.Add(group, field, e)
+}
+
+// Check records msg as the failure of field when cond doesn't hold, see
+// Catalog.Check.
+func (s *SafeCatalog) Check(cond bool, group, field, msg string) bool {
+	if !cond {
+		s.AddField(group, field, "%s", msg)
+	}
+
+	return cond
+}
+
+// Ensure runs fn and records its failure as the failure of field, see
+// Catalog.Ensure. fn runs without holding the lock.
+func (s *SafeCatalog) Ensure(fn func() error, group, field string) bool {
+	err := fn()
+	s.Add(group, field, err)
+
+	return err == nil
+}
+
+// ErrorCount returns the number of failed fields
+func (s *SafeCatalog) ErrorCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.ErrorCount()
+}
+
+// Catalog returns a snapshot of the fields recorded so far, later additions
+// don't change it.
+func (s *SafeCatalog) Catalog() *Catalog {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c := NewCatalog(s.c.Key, s.c.Status)
+	for _, g := range s.c.fieldsByGroup() {
+		for i := range g.fields {
+			f := g.fields[i]
+			c.setField(g.name, &f)
+		}
+	}
+	return c
+}
+
+// ErrorOrNil returns a snapshot of the catalog as an error, or nil when no
+// field failed, see Catalog.ErrorOrNil.
+func (s *SafeCatalog) ErrorOrNil() error {
+	return s.Catalog().ErrorOrNil()
+}
+
+// fieldsByGroup lists the failed fields of c by group in insertion order.
+// Groups and fields set directly on Groups and Fields come after the added
+// ones, sorted by name.
+func (c *Catalog) fieldsByGroup() fieldsByGroup {
+	if c == nil {
+		return nil
+	}
+
+	var out fieldsByGroup
+	for _, name := range inOrder(c.order, c.Groups) {
+		g := c.Groups[name]
+		if g == nil || len(g.Fields) == 0 {
+			continue
+		}
+
+		entry := groupFields{name: name}
+		for _, k := range inOrder(g.order, g.Fields) {
+			if f := g.Fields[k]; f != nil {
+				x := *f
+				x.Name = k
+				entry.fields = append(entry.fields, x)
+			}
+		}
+		out = append(out, entry)
+	}
+
+	return out
+}
+
+// inOrder returns the keys of m listed in order first, then the others
+// sorted.
+func inOrder[V any](order []string, m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, k := range order {
+		if _, ok := m[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+
+	var rest []string
+	for k := range m {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// groupFields are the failed fields of a group in insertion order
+type groupFields struct {
+	name   string
+	fields []Field
+}
+
+// fieldsByGroup are the messages of failed fields by group, encoded as
+// nested JSON objects that keep their order.
+type fieldsByGroup []groupFields
+
+// MarshalJSON writes {"group":{"field":"msg"}} in order
+func (x fieldsByGroup) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, g := range x {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(&buf, g.name)
+		buf.WriteString(":{")
+		for j, f := range g.fields {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(&buf, f.Name)
+			buf.WriteByte(':')
+			writeJSONString(&buf, f.Msg)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads the nested objects written by MarshalJSON keeping
+// the order of their members.
+func (x *fieldsByGroup) UnmarshalJSON(data []byte) error {
+	*x = nil
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		var g groupFields
+		if err := dec.Decode(&g.name); err != nil {
+			return err
+		}
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+		for dec.More() {
+			var f Field
+			if err := dec.Decode(&f.Name); err != nil {
+				return err
+			}
+			if err := dec.Decode(&f.Msg); err != nil {
+				return err
+			}
+			g.fields = append(g.fields, f)
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return err
+		}
+		*x = append(*x, g)
+	}
+
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return InvalidParam("expected %q, got %v", want, tok)
+	}
+
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	buf.Write(data)
+}
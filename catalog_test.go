@@ -0,0 +1,196 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	c := failure.NewCatalog("create_user", 0)
+	assert.Equal(t, http.StatusUnprocessableEntity, c.Status)
+	assert.NoError(t, c.ErrorOrNil())
+	assert.Zero(t, c.ErrorCount())
+
+	c.AddField("body", "email", "must contain an @")
+	c.AddField("body", "age", "must be at least %d", 18)
+	c.Add("query", "page", failure.BadRequest("not a number"))
+	c.Add("query", "limit", nil)
+
+	assert.Equal(t, 3, c.ErrorCount())
+	assert.Equal(t, map[string]map[string]string{
+		"body":  {"email": "must contain an @", "age": "must be at least 18"},
+		"query": {"page": "not a number"},
+	}, c.AllFailures())
+
+	err := c.ErrorOrNil()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, failure.KindInvalidAPIFields))
+	assert.Equal(t, "create_user: 3 invalid fields (body.email: must contain an @, "+
+		"body.age: must be at least 18, query.page: not a number): "+failure.InvalidAPIFieldsMsg, err.Error())
+
+	var nilCatalog *failure.Catalog
+	assert.Zero(t, nilCatalog.ErrorCount())
+	assert.NoError(t, nilCatalog.ErrorOrNil())
+}
+
+func TestCatalog_CheckEnsure(t *testing.T) {
+	validate := func(email string, age int) error {
+		c := failure.NewCatalog("create_user", 0)
+		if c.Check(email != "", "body", "email", "is required") {
+			c.Check(strings.Contains(email, "@"), "body", "email", "must contain an @")
+		}
+		c.Check(age >= 18, "body", "age", "must be at least 18")
+		c.Ensure(func() error {
+			if email == "taken@example.com" {
+				return failure.AlreadyExists("email is taken")
+			}
+			return nil
+		}, "body", "email")
+
+		return c.ErrorOrNil()
+	}
+
+	assert.NoError(t, validate("a@example.com", 30))
+
+	err := validate("", 12)
+	var c *failure.Catalog
+	require.ErrorAs(t, err, &c)
+	assert.Equal(t, map[string]map[string]string{
+		"body": {"email": "is required", "age": "must be at least 18"},
+	}, c.AllFailures())
+
+	err = validate("taken@example.com", 30)
+	require.ErrorAs(t, err, &c)
+	assert.Contains(t, c.AllFailures()["body"]["email"], "email is taken")
+
+	assert.True(t, c.Ensure(func() error { return nil }, "body", "name"))
+	assert.False(t, c.Check(false, "body", "name", "is required"))
+}
+
+func TestValidateSlice(t *testing.T) {
+	type row struct {
+		Email string
+		Age   int
+	}
+
+	rows := []row{
+		{Email: "a@example.com", Age: 30},
+		{Email: "", Age: 30},
+		{Email: "b@example.com", Age: 30},
+		{Email: "c", Age: 12},
+	}
+
+	c := failure.ValidateSlice(rows, func(r row) error {
+		if r.Email == "" {
+			return failure.Validation("email is required")
+		}
+		nested := failure.NewCatalog("row", 0)
+		nested.Check(strings.Contains(r.Email, "@"), "", "email", "must contain an @")
+		nested.Check(r.Age >= 18, "", "age", "must be at least 18")
+		return nested.ErrorOrNil()
+	})
+
+	assert.Equal(t, 3, c.ErrorCount())
+	failures := c.AllFailures()[""]
+	assert.Contains(t, failures["items[1]"], "email is required")
+	assert.Equal(t, "must contain an @", failures["items[3].email"])
+	assert.Equal(t, "must be at least 18", failures["items[3].age"])
+	assert.Contains(t, c.Error(), "items[3].age: must be at least 18")
+
+	assert.NoError(t, failure.ValidateSlice([]int{1, 2}, func(int) error { return nil }).ErrorOrNil())
+}
+
+func TestCatalog_JSON(t *testing.T) {
+	c := failure.NewCatalog("create_user", 0)
+	c.AddField("body", "email", "is required")
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"key":"create_user","status":422,"errors":{"body":{"email":"is required"}}}`, string(data))
+
+	data, err = json.Marshal(failure.NewCatalog("", http.StatusBadRequest))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"key":"","status":400,"errors":{}}`, string(data))
+
+	var out failure.Catalog
+	require.NoError(t, json.Unmarshal([]byte(`{"key":"create_user","errors":{"body":{"email":"is required"}}}`), &out))
+	assert.Equal(t, "create_user", out.Key)
+	assert.Equal(t, http.StatusUnprocessableEntity, out.Status)
+	assert.Equal(t, c.AllFailures(), out.AllFailures())
+	assert.True(t, errors.Is(&out, failure.KindInvalidAPIFields))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"errors":[]}`), &out))
+}
+
+func TestCatalog_Order(t *testing.T) {
+	c := failure.NewCatalog("create_user", 0)
+	c.AddField("query", "page", "not a number")
+	c.AddField("body", "name", "is required")
+	c.AddField("body", "email", "is required")
+	c.AddField("query", "page", "must be positive")
+
+	assert.Equal(t, "create_user: 3 invalid fields (query.page: must be positive, "+
+		"body.name: is required, body.email: is required): "+failure.InvalidAPIFieldsMsg, c.Error())
+
+	want := `{"key":"create_user","status":422,"errors":{"query":{"page":"must be positive"},` +
+		`"body":{"name":"is required","email":"is required"}}}`
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(data))
+
+	var out failure.Catalog
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, c.Error(), out.Error())
+
+	data, err = failure.Encode(c)
+	require.NoError(t, err)
+	decoded, err := failure.Decode(data)
+	require.NoError(t, err)
+	var back *failure.Catalog
+	require.True(t, errors.As(decoded, &back))
+	assert.Equal(t, c.Error(), back.Error())
+}
+
+func TestSafeCatalog(t *testing.T) {
+	s := failure.NewSafeCatalog("create_user", 0)
+	assert.NoError(t, s.ErrorOrNil())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			field := "f" + strconv.Itoa(i)
+			switch i % 3 {
+			case 0:
+				s.AddField("body", field, "is required")
+			case 1:
+				s.Check(false, "query", field, "not a number")
+			default:
+				s.Ensure(func() error { return failure.BadRequest("bad") }, "header", field)
+			}
+			_ = s.ErrorCount()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, s.ErrorCount())
+
+	c := s.Catalog()
+	s.AddField("body", "late", "is required")
+	assert.Equal(t, 50, c.ErrorCount())
+	assert.Equal(t, http.StatusUnprocessableEntity, c.Status)
+
+	err := s.ErrorOrNil()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, failure.KindInvalidAPIFields))
+}
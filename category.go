@@ -0,0 +1,305 @@
+package failure
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Category identifies the kind of failure an error represents. Every
+// constructor in this package places one at the root of the error chain,
+// which is what the IsX functions look for with errors.Is.
+type Category string
+
+const (
+	KindSystem             Category = "system"
+	KindServer             Category = "server"
+	KindShutdown           Category = "shutdown"
+	KindConfig             Category = "config"
+	KindNotFound           Category = "not_found"
+	KindNotAuthorized      Category = "not_authorized"
+	KindNotAuthenticated   Category = "not_authenticated"
+	KindForbidden          Category = "forbidden"
+	KindValidation         Category = "validation"
+	KindInvalidParam       Category = "invalid_param"
+	KindDefer              Category = "defer"
+	KindIgnore             Category = "ignore"
+	KindTimeout            Category = "timeout"
+	KindStartup            Category = "startup"
+	KindPanic              Category = "panic"
+	KindBadRequest         Category = "bad_request"
+	KindInvalidAPIFields   Category = "invalid_api_fields"
+	KindMissingFromContext Category = "missing_from_context"
+	KindAlreadyExists      Category = "already_exists"
+	KindOutOfRange         Category = "out_of_range"
+	KindWarn               Category = "warn"
+	KindNoChange           Category = "no_change"
+	KindInvalidState       Category = "invalid_state"
+	KindCanceled           Category = "canceled"
+	KindOverloaded         Category = "overloaded"
+	KindExpired            Category = "expired"
+	KindRateLimited        Category = "rate_limited"
+	KindConflict           Category = "conflict"
+	KindNotImplemented     Category = "not_implemented"
+	KindUnavailable        Category = "unavailable"
+	KindResourceExhausted  Category = "resource_exhausted"
+	KindPayloadTooLarge    Category = "payload_too_large"
+)
+
+// categoryInfo describes the defaults of a category
+type categoryInfo struct {
+	msg       string
+	severity  Severity
+	retryable bool
+	// status is the HTTP response status
+	status int
+	grpc   codes.Code
+}
+
+var categories = map[Category]categoryInfo{
+	KindSystem: {
+		msg: SystemMsg, severity: SeverityError,
+		status: http.StatusInternalServerError, grpc: codes.Internal,
+	},
+	KindServer: {
+		msg: ServerMsg, severity: SeverityError,
+		status: http.StatusInternalServerError, grpc: codes.Internal,
+	},
+	KindShutdown: {
+		msg: ShutdownMsg, severity: SeverityInfo,
+		status: http.StatusServiceUnavailable, grpc: codes.Unavailable,
+	},
+	KindConfig: {
+		msg: ConfigMsg, severity: SeverityCritical,
+		status: http.StatusInternalServerError, grpc: codes.Internal,
+	},
+	KindNotFound: {
+		msg: NotFoundMsg, severity: SeverityWarning,
+		status: http.StatusNotFound, grpc: codes.NotFound,
+	},
+	KindNotAuthorized: {
+		msg: NotAuthorizedMsg, severity: SeverityWarning,
+		status: http.StatusForbidden, grpc: codes.PermissionDenied,
+	},
+	KindNotAuthenticated: {
+		msg: NotAuthenticatedMsg, severity: SeverityWarning,
+		status: http.StatusUnauthorized, grpc: codes.Unauthenticated,
+	},
+	KindForbidden: {
+		msg: ForbiddenMsg, severity: SeverityWarning,
+		status: http.StatusForbidden, grpc: codes.PermissionDenied,
+	},
+	KindValidation: {
+		msg: ValidationMsg, severity: SeverityWarning,
+		status: http.StatusUnprocessableEntity, grpc: codes.InvalidArgument,
+	},
+	KindInvalidParam: {
+		msg: InvalidParamMsg, severity: SeverityWarning,
+		status: http.StatusBadRequest, grpc: codes.InvalidArgument,
+	},
+	KindDefer: {
+		msg: DeferMsg, severity: SeverityError,
+		status: http.StatusInternalServerError, grpc: codes.Internal,
+	},
+	KindIgnore: {
+		msg: IgnoreMsg, severity: SeverityDebug,
+		status: http.StatusInternalServerError, grpc: codes.Unknown,
+	},
+	KindTimeout: {
+		msg: TimeoutMsg, severity: SeverityError, retryable: true,
+		status: http.StatusGatewayTimeout, grpc: codes.DeadlineExceeded,
+	},
+	KindStartup: {
+		msg: StartupMsg, severity: SeverityCritical,
+		status: http.StatusServiceUnavailable, grpc: codes.Unavailable,
+	},
+	KindPanic: {
+		msg: PanicMsg, severity: SeverityCritical,
+		status: http.StatusInternalServerError, grpc: codes.Internal,
+	},
+	KindBadRequest: {
+		msg: BadRequestMsg, severity: SeverityWarning,
+		status: http.StatusBadRequest, grpc: codes.InvalidArgument,
+	},
+	KindInvalidAPIFields: {
+		msg: InvalidAPIFieldsMsg, severity: SeverityWarning,
+		status: http.StatusUnprocessableEntity, grpc: codes.InvalidArgument,
+	},
+	KindMissingFromContext: {
+		msg: MissingFromContextMsg, severity: SeverityError,
+		status: http.StatusInternalServerError, grpc: codes.Internal,
+	},
+	KindAlreadyExists: {
+		msg: AlreadyExistsMsg, severity: SeverityWarning,
+		status: http.StatusConflict, grpc: codes.AlreadyExists,
+	},
+	KindOutOfRange: {
+		msg: OutOfRangeMsg, severity: SeverityWarning,
+		status: http.StatusBadRequest, grpc: codes.OutOfRange,
+	},
+	KindWarn: {
+		msg: WarnMsg, severity: SeverityWarning,
+		status: http.StatusInternalServerError, grpc: codes.Unknown,
+	},
+	KindNoChange: {
+		msg: NoChangeMsg, severity: SeverityInfo,
+		status: http.StatusInternalServerError, grpc: codes.Unknown,
+	},
+	KindInvalidState: {
+		msg: InvalidStateMsg, severity: SeverityError,
+		status: http.StatusBadRequest, grpc: codes.FailedPrecondition,
+	},
+	KindCanceled: {
+		msg: CanceledMsg, severity: SeverityInfo,
+		status: statusClientClosedRequest, grpc: codes.Canceled,
+	},
+	KindOverloaded: {
+		msg: OverloadedMsg, severity: SeverityWarning, retryable: true,
+		status: http.StatusServiceUnavailable, grpc: codes.Unavailable,
+	},
+	KindExpired: {
+		msg: ExpiredMsg, severity: SeverityInfo,
+		status: http.StatusGone, grpc: codes.FailedPrecondition,
+	},
+	KindRateLimited: {
+		msg: RateLimitedMsg, severity: SeverityWarning, retryable: true,
+		status: http.StatusTooManyRequests, grpc: codes.ResourceExhausted,
+	},
+	KindConflict: {
+		msg: ConflictMsg, severity: SeverityWarning,
+		status: http.StatusConflict, grpc: codes.Aborted,
+	},
+	KindNotImplemented: {
+		msg: NotImplementedMsg, severity: SeverityWarning,
+		status: http.StatusNotImplemented, grpc: codes.Unimplemented,
+	},
+	KindUnavailable: {
+		msg: UnavailableMsg, severity: SeverityError, retryable: true,
+		status: http.StatusServiceUnavailable, grpc: codes.Unavailable,
+	},
+	KindResourceExhausted: {
+		msg: ResourceExhaustedMsg, severity: SeverityError, retryable: true,
+		status: http.StatusTooManyRequests, grpc: codes.ResourceExhausted,
+	},
+	KindPayloadTooLarge: {
+		msg: PayloadTooLargeMsg, severity: SeverityWarning,
+		status: http.StatusRequestEntityTooLarge, grpc: codes.ResourceExhausted,
+	},
+}
+
+// customCategories holds the categories created with NewKind
+var customCategories = struct {
+	sync.RWMutex
+	infos map[Category]categoryInfo
+}{infos: map[Category]categoryInfo{}}
+
+// categoryInfoOf returns the defaults of c, built in or created with
+// NewKind.
+func categoryInfoOf(c Category) (categoryInfo, bool) {
+	if info, ok := categories[c]; ok {
+		return info, true
+	}
+
+	customCategories.RLock()
+	defer customCategories.RUnlock()
+	info, ok := customCategories.infos[c]
+	return info, ok
+}
+
+// allCategories returns the defaults of every category, built in or
+// created with NewKind.
+func allCategories() map[Category]categoryInfo {
+	customCategories.RLock()
+	defer customCategories.RUnlock()
+
+	out := make(map[Category]categoryInfo, len(categories)+len(customCategories.infos))
+	for c, info := range categories {
+		out[c] = info
+	}
+	for c, info := range customCategories.infos {
+		out[c] = info
+	}
+
+	return out
+}
+
+var categoryMessages = struct {
+	sync.RWMutex
+	overrides map[Category]string
+}{overrides: map[Category]string{}}
+
+// SetCategoryMessage replaces the canonical message of a category, for
+// translations or house style. Classification is done on the category and
+// not its message, so errors.Is and the IsX functions are unaffected. Only
+// failures created afterwards carry the new message. An empty msg restores
+// the default.
+func SetCategoryMessage(c Category, msg string) {
+	categoryMessages.Lock()
+	defer categoryMessages.Unlock()
+
+	if msg == "" {
+		delete(categoryMessages.overrides, c)
+		return
+	}
+	categoryMessages.overrides[c] = msg
+}
+
+// Error returns the canonical message of the category, which is what ends
+// up at the tail of every failure built from it.
+func (c Category) Error() string {
+	categoryMessages.RLock()
+	msg, ok := categoryMessages.overrides[c]
+	categoryMessages.RUnlock()
+	if ok {
+		return msg
+	}
+
+	if info, ok := categoryInfoOf(c); ok {
+		return info.msg
+	}
+
+	return string(c)
+}
+
+// String returns the name of the category
+func (c Category) String() string {
+	return string(c)
+}
+
+// Kind returns the outermost category found in the chain of e, it is the
+// category the failure is handled as. Switching over it reads better than
+// a chain of IsX calls in routing and metrics code:
+//
+//	switch c, _ := failure.Kind(err); c {
+//	case failure.KindNotFound:
+//		...
+//	case failure.KindTimeout, failure.KindCanceled:
+//		...
+//	}
+func Kind(e error) (Category, bool) {
+	var c Category
+	var found bool
+	Walk(e, func(x error) bool {
+		c, found = x.(Category)
+		return !found
+	})
+
+	return c, found
+}
+
+// Kinds lists every category, the built in ones and those created with
+// NewKind, sorted by name.
+func Kinds() []Category {
+	all := allCategories()
+	list := make([]Category, 0, len(all))
+	for c := range all {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+
+	return list
+}
@@ -0,0 +1,86 @@
+package failure_test
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCategoryMessage(t *testing.T) {
+	before := failure.NotFound("usuario 1")
+
+	failure.SetCategoryMessage(failure.KindNotFound, "recurso no encontrado")
+	defer failure.SetCategoryMessage(failure.KindNotFound, "")
+
+	err := failure.NotFound("usuario 1")
+	assert.Equal(t, "usuario 1: recurso no encontrado", err.Error())
+	assert.True(t, failure.IsNotFound(err))
+	assert.True(t, errors.Is(err, failure.KindNotFound))
+	assert.Equal(t, "usuario 1: "+failure.NotFoundMsg, before.Error())
+
+	for _, d := range failure.Taxonomy() {
+		if d.Name == "not_found" {
+			assert.Equal(t, "recurso no encontrado", d.Message)
+		}
+	}
+
+	failure.SetCategoryMessage(failure.KindNotFound, "")
+	assert.Equal(t, failure.NotFoundMsg, failure.KindNotFound.Error())
+}
+
+func TestKind(t *testing.T) {
+	c, ok := failure.Kind(failure.Wrap(failure.NotFound("user 1"), "load"))
+	assert.True(t, ok)
+	assert.Equal(t, failure.KindNotFound, c)
+
+	c, ok = failure.Kind(failure.ToSystem(failure.Timeout("db"), "query"))
+	assert.True(t, ok)
+	assert.Equal(t, failure.KindSystem, c)
+
+	_, ok = failure.Kind(errors.New("raw"))
+	assert.False(t, ok)
+	_, ok = failure.Kind(nil)
+	assert.False(t, ok)
+}
+
+func TestKinds(t *testing.T) {
+	kinds := failure.Kinds()
+	assert.Contains(t, kinds, failure.KindNotFound)
+	assert.Contains(t, kinds, quotaExceeded.Category())
+	assert.Len(t, kinds, len(failure.Taxonomy()))
+	assert.True(t, sort.SliceIsSorted(kinds, func(i, j int) bool { return kinds[i] < kinds[j] }))
+}
+
+var quotaExceeded = failure.NewKind("Quota exceeded",
+	failure.KindStatus(http.StatusTooManyRequests),
+	failure.KindSeverity(failure.SeverityWarning),
+	failure.KindRetryable())
+
+func TestNewKind(t *testing.T) {
+	assert.Equal(t, failure.Category("quota_exceeded"), quotaExceeded.Category())
+
+	err := quotaExceeded.New("tenant %s", "acme")
+	assert.Equal(t, "tenant acme: Quota exceeded", err.Error())
+	assert.True(t, quotaExceeded.Is(err))
+	assert.False(t, quotaExceeded.Is(failure.NotFound("user")))
+	assert.False(t, failure.IsNotFound(err))
+
+	c, ok := failure.Kind(err)
+	assert.True(t, ok)
+	assert.Equal(t, quotaExceeded.Category(), c)
+	assert.Equal(t, http.StatusTooManyRequests, failure.HTTPStatus(err))
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(err))
+	assert.True(t, failure.IsRetryable(err))
+
+	cause := errors.New("429 from billing")
+	err = quotaExceeded.To(cause, "charge")
+	assert.True(t, quotaExceeded.Is(err))
+	assert.True(t, errors.Is(err, cause))
+
+	assert.Panics(t, func() { failure.NewKind("not found") })
+	assert.Panics(t, func() { failure.NewKind("quota-exceeded") })
+}
@@ -0,0 +1,48 @@
+package failure
+
+type originKey struct{}
+
+// fromError builds the cause of a ToX failure with x and the message of e,
+// masked by the Redactor and never read as a format. Only the message is kept so e doesn't match
+// errors.Is and errors.As, e is recorded as the origin for RootCause.
+func fromError(e error, x func(string, ...interface{}) error) error {
+	return annotate(x("%s", redactMessage(e.Error())), originKey{}, e)
+}
+
+// RootCause returns the innermost error of the chain of e that is not a
+// category, the third-party error given to ToX or To rather than the
+// failures wrapping it, so an alert can show what actually went wrong next
+// to how it was classified:
+//
+//	log.Error("sync failed", "category", failure.CategoryOf(err), "cause", failure.RootCause(err))
+//
+// The walk stops at a Multi, which is returned as is since its members
+// have causes of their own. nil is returned when e is nil.
+func RootCause(e error) error {
+	root := e
+	for e != nil {
+		switch x := e.(type) {
+		case Category:
+			return root
+		case *Multi:
+			return x
+		case *annotation:
+			if x.key == (originKey{}) {
+				root, e = x.value.(error), x.value.(error)
+				continue
+			}
+		}
+
+		root = e
+		e = next(e)
+	}
+
+	return root
+}
+
+// CategoryOf returns the category of e, see Kind, or the zero Category
+// when it has none.
+func CategoryOf(e error) Category {
+	c, _ := Kind(e)
+	return c
+}
@@ -0,0 +1,37 @@
+package failure_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootCause(t *testing.T) {
+	driver := errors.New("dial tcp 10.0.0.1:5432: connection refused")
+
+	err := failure.Wrap(failure.ToSystem(fmt.Errorf("connect: %w", driver), "open db"), "load user")
+	assert.Same(t, driver, failure.RootCause(err))
+	assert.False(t, errors.Is(err, driver))
+	assert.Equal(t, failure.KindSystem, failure.CategoryOf(err))
+
+	err = failure.ToTimeout(failure.ToSystem(driver, "open db"), "handle")
+	assert.Same(t, driver, failure.RootCause(err))
+	assert.Equal(t, failure.KindTimeout, failure.CategoryOf(err))
+
+	err = failure.To(failure.KindUnavailable, driver, "open db")
+	assert.Same(t, driver, failure.RootCause(err))
+
+	err = failure.Wrap(failure.NotFound("user %s", "42"), "load")
+	assert.Equal(t, "user 42: "+failure.NotFoundMsg, failure.RootCause(err).Error())
+
+	m := failure.Append(driver, failure.System("other"))
+	assert.Same(t, m, failure.RootCause(failure.Wrap(m, "batch")))
+
+	assert.Same(t, driver, failure.RootCause(driver))
+	assert.Nil(t, failure.RootCause(nil))
+	assert.Equal(t, failure.KindNotFound, failure.RootCause(failure.KindNotFound))
+	assert.Zero(t, failure.CategoryOf(driver))
+}
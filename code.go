@@ -0,0 +1,108 @@
+package failure
+
+import (
+	"sort"
+	"sync"
+)
+
+type codeKey struct{}
+
+// WithCode returns e carrying a stable machine readable code, such as
+// "ORD-001", that API consumers and support teams can key off instead of
+// the message. The code is written in the ErrorResponse and Problem of e.
+func WithCode(e error, code string) error {
+	return annotate(e, codeKey{}, code)
+}
+
+// Code returns the code set with WithCode, the outermost one when it was
+// set more than once.
+func Code(e error) (string, bool) {
+	v, ok := lookup(e, codeKey{})
+	if !ok {
+		return "", false
+	}
+
+	return v.(string), true
+}
+
+// CodeInfo describes a code of a Registry
+type CodeInfo struct {
+	Code        string   `json:"code" yaml:"code"`
+	Category    Category `json:"category,omitempty" yaml:"category,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Registry binds codes to the category and description of the failures
+// they identify, making sure no code is used twice. Services typically
+// keep one in a package variable:
+//
+//	var codes = failure.NewRegistry()
+//
+//	var ErrOrderMissing = codes.MustRegister("ORD-001", failure.KindNotFound, "the order does not exist")
+//
+//	return failure.WithCode(failure.NotFound("order %s", id), ErrOrderMissing)
+//
+// Codes lists them all, for documentation or a support endpoint. A
+// Registry is safe for concurrent use.
+type Registry struct {
+	mutex sync.RWMutex
+	codes map[string]CodeInfo
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{codes: map[string]CodeInfo{}}
+}
+
+// Register adds code, it returns an AlreadyExists failure when the code is
+// registered already and an InvalidParam failure when it is empty.
+func (r *Registry) Register(code string, cat Category, description string) error {
+	if code == "" {
+		return InvalidParam("failure code is empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.codes[code]; ok {
+		return AlreadyExists("failure code %s", code)
+	}
+	r.codes[code] = CodeInfo{Code: code, Category: cat, Description: description}
+
+	return nil
+}
+
+// MustRegister is Register panicking on failure, for package variables. It
+// returns the code.
+func (r *Registry) MustRegister(code string, cat Category, description string) string {
+	if err := r.Register(code, cat, description); err != nil {
+		panic(err)
+	}
+
+	return code
+}
+
+// Lookup returns the description of code
+func (r *Registry) Lookup(code string) (CodeInfo, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	info, ok := r.codes[code]
+	return info, ok
+}
+
+// Codes returns every registered code, sorted
+func (r *Registry) Codes() []CodeInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	list := make([]CodeInfo, 0, len(r.codes))
+	for _, info := range r.codes {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+
+	return list
+}
@@ -0,0 +1,45 @@
+package failure_test
+
+import (
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCode(t *testing.T) {
+	err := failure.NotFound("order 7")
+	_, ok := failure.Code(err)
+	assert.False(t, ok)
+
+	err = failure.Wrap(failure.WithCode(err, "ORD-001"), "get order")
+	code, ok := failure.Code(err)
+	assert.True(t, ok)
+	assert.Equal(t, "ORD-001", code)
+	assert.True(t, failure.IsNotFound(err))
+
+	assert.Equal(t, "ORD-001", failure.NewErrorResponse(err).Code)
+	assert.Equal(t, "ORD-001", failure.NewProblem(err).Code)
+}
+
+func TestRegistry(t *testing.T) {
+	r := failure.NewRegistry()
+	missing := r.MustRegister("ORD-001", failure.KindNotFound, "the order does not exist")
+	assert.Equal(t, "ORD-001", missing)
+	require.NoError(t, r.Register("ORD-000", failure.KindValidation, "the order is invalid"))
+
+	err := r.Register("ORD-001", failure.KindConflict, "")
+	assert.True(t, failure.IsAlreadyExists(err))
+	assert.True(t, failure.IsInvalidParam(r.Register("", failure.KindConflict, "")))
+	assert.Panics(t, func() { r.MustRegister("ORD-001", failure.KindNotFound, "") })
+
+	info, ok := r.Lookup("ORD-001")
+	assert.True(t, ok)
+	assert.Equal(t, failure.KindNotFound, info.Category)
+
+	assert.Equal(t, []failure.CodeInfo{
+		{Code: "ORD-000", Category: failure.KindValidation, Description: "the order is invalid"},
+		{Code: "ORD-001", Category: failure.KindNotFound, Description: "the order does not exist"},
+	}, r.Codes())
+}
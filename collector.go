@@ -0,0 +1,81 @@
+package failure
+
+import "sync"
+
+// Collector centralizes the failures produced by streaming pipelines. It
+// consumes error channels until they close, accumulating the failures into a
+// Multi and keeping live counts by category. It is safe for concurrent use.
+type Collector struct {
+	wg     sync.WaitGroup
+	mutex  sync.Mutex
+	err    *Multi
+	counts map[Category]int
+}
+
+// Collect creates a Collector consuming every one of chs
+func Collect(chs ...<-chan error) *Collector {
+	c := Collector{counts: map[Category]int{}}
+	for _, ch := range chs {
+		c.Add(ch)
+	}
+
+	return &c
+}
+
+// Add starts consuming ch, nil errors received from it are skipped. Add
+// must not be called once Wait has returned.
+func (c *Collector) Add(ch <-chan error) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for err := range ch {
+			if err != nil {
+				c.record(err)
+			}
+		}
+	}()
+}
+
+// Counts returns a snapshot of the number of failures received so far by
+// category. Errors without a category are counted under the empty Category.
+func (c *Collector) Counts() map[Category]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	out := make(map[Category]int, len(c.counts))
+	for k, v := range c.counts {
+		out[k] = v
+	}
+
+	return out
+}
+
+// Len returns the number of failures received so far
+func (c *Collector) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err == nil {
+		return 0
+	}
+	return c.err.Len()
+}
+
+// Wait blocks until every channel is closed and returns the accumulated
+// failures, nil when there were none.
+func (c *Collector) Wait() *Multi {
+	c.wg.Wait()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+func (c *Collector) record(err error) {
+	kind, _ := Kind(err)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.err = Append(c.err, err)
+	c.counts[kind]++
+}
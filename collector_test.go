@@ -0,0 +1,50 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	a := make(chan error)
+	b := make(chan error)
+	c := failure.Collect(a, b)
+	assert.Equal(t, 0, c.Len())
+
+	go func() {
+		a <- failure.Timeout("slow")
+		a <- nil
+		a <- failure.Timeout("slower")
+		close(a)
+	}()
+	go func() {
+		b <- failure.Validation("bad row")
+		b <- errors.New("plain")
+		close(b)
+	}()
+
+	result := c.Wait()
+	require.NotNil(t, result)
+	assert.Len(t, result.Failures, 4)
+	assert.Equal(t, 4, c.Len())
+
+	assert.Equal(t, map[failure.Category]int{
+		failure.KindTimeout:    2,
+		failure.KindValidation: 1,
+		"":                     1,
+	}, c.Counts())
+}
+
+func TestCollector_Empty(t *testing.T) {
+	ch := make(chan error)
+	close(ch)
+
+	c := failure.Collect()
+	c.Add(ch)
+	assert.Nil(t, c.Wait())
+	assert.Empty(t, c.Counts())
+}
@@ -0,0 +1,134 @@
+package failure
+
+import (
+	"context"
+	"errors"
+)
+
+// WrapContext wraps err with the message and classifies it from the state of
+// ctx: when the deadline of ctx has passed the result is a Timeout, when ctx
+// was canceled it is Canceled, otherwise err is only wrapped. The original
+// error stays reachable with errors.Is and errors.As. nil is returned when
+// err is nil. A cause given to the context with context.WithTimeoutCause or
+// context.WithCancelCause is added to the failure, see WithDeadlineCause.
+//
+//	out, err := client.Do(ctx, in)
+//	if err != nil {
+//		return failure.WrapContext(ctx, err, "client.Do failed for (%s)", in.ID)
+//	}
+func WrapContext(ctx context.Context, err error, format string, a ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	cause := ctx.Err()
+	if cause == nil {
+		cause = err
+	}
+
+	switch {
+	case errors.Is(cause, context.DeadlineExceeded):
+		return Wrap(&classified{err: err, kind: KindTimeout, cause: customCause(ctx)}, format, a...)
+	case errors.Is(cause, context.Canceled):
+		return Wrap(&classified{err: err, kind: KindCanceled, cause: customCause(ctx)}, format, a...)
+	default:
+		return Wrap(err, format, a...)
+	}
+}
+
+// FromContext returns the failure of a done ctx, a Timeout when its
+// deadline passed and Canceled when it was canceled, carrying the cause of
+// ctx like WrapContext. nil is returned while ctx is not done, so loops can
+// end with:
+//
+//	if err := failure.FromContext(ctx); err != nil {
+//		return err
+//	}
+func FromContext(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+
+	return fromContextErr(err, customCause(ctx))
+}
+
+// FromContextErr classifies a context error, context.DeadlineExceeded
+// becomes a Timeout and context.Canceled becomes Canceled, wrapped errors
+// included. The original error stays reachable with errors.Is, any other
+// error is returned unchanged.
+func FromContextErr(err error) error {
+	return fromContextErr(err, nil)
+}
+
+func fromContextErr(err, cause error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &netTimeout{err: &classified{err: err, kind: KindTimeout, cause: cause}}
+	case errors.Is(err, context.Canceled):
+		return &classified{err: err, kind: KindCanceled, cause: cause}
+	default:
+		return err
+	}
+}
+
+// WithDeadlineCause classifies err as a Timeout when the deadline of ctx
+// has fired, carrying the cause configured with context.WithTimeoutCause or
+// context.WithDeadlineCause so the failure says which budget ran out:
+//
+//	ctx, cancel := context.WithTimeoutCause(ctx, time.Second, errQueryBudget)
+//	defer cancel()
+//	if err := db.QueryRowContext(ctx, q).Scan(&v); err != nil {
+//		return failure.WithDeadlineCause(ctx, err)
+//	}
+//
+// The cause stays reachable with errors.Is. err is returned unchanged when
+// the deadline hasn't fired and nil is returned when err is nil.
+func WithDeadlineCause(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	return &netTimeout{err: &classified{err: err, kind: KindTimeout, cause: customCause(ctx)}}
+}
+
+// customCause returns the cause of ctx when it is more than the plain
+// context error.
+func customCause(ctx context.Context) error {
+	cause := context.Cause(ctx)
+	if cause == nil || cause == ctx.Err() {
+		return nil
+	}
+
+	return cause
+}
+
+// classify wraps e with the message, classifying it as kind while keeping
+// e itself in the chain. The message reads the same as the ToX functions,
+// masked by the Redactor when one is set.
+func classify(e error, kind Category, format string, a ...interface{}) error {
+	return Wrap(&classified{err: redactable(e), kind: kind}, format, a...)
+}
+
+// classified is an error given a new category. The category comes first
+// when unwrapping so it takes precedence over any category e already had.
+// An optional cause explains why e was given the category.
+type classified struct {
+	err   error
+	kind  Category
+	cause error
+}
+
+func (c *classified) Error() string {
+	if c.cause != nil {
+		return c.err.Error() + ": " + c.cause.Error() + ": " + c.kind.Error()
+	}
+	return c.err.Error() + ": " + c.kind.Error()
+}
+
+func (c *classified) Unwrap() []error {
+	if c.cause != nil {
+		return []error{c.kind, c.err, c.cause}
+	}
+	return []error{c.kind, c.err}
+}
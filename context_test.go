@@ -0,0 +1,124 @@
+package failure_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapContext(t *testing.T) {
+	api := errors.New("read tcp: i/o timeout")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := failure.WrapContext(ctx, api, "client.Do failed for (%s)", "abc")
+	assert.True(t, failure.IsTimeout(err))
+	assert.True(t, errors.Is(err, api))
+	assert.Equal(t, "client.Do failed for (abc): read tcp: i/o timeout: "+failure.TimeoutMsg, err.Error())
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = failure.WrapContext(ctx, api, "client.Do failed")
+	assert.True(t, failure.IsCanceled(err))
+	assert.False(t, failure.IsTimeout(err))
+	assert.True(t, errors.Is(err, api))
+
+	err = failure.WrapContext(context.Background(), api, "client.Do failed")
+	assert.False(t, failure.IsCanceled(err))
+	assert.False(t, failure.IsTimeout(err))
+	assert.Equal(t, "client.Do failed: read tcp: i/o timeout", err.Error())
+
+	err = failure.WrapContext(context.Background(), context.DeadlineExceeded, "query")
+	assert.True(t, failure.IsTimeout(err))
+
+	assert.Nil(t, failure.WrapContext(ctx, nil, "foo"))
+}
+
+func TestWrapContext_Reclassifies(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := failure.WrapContext(ctx, failure.NotFound("user 1"), "load user")
+	kind, ok := failure.Kind(err)
+	assert.True(t, ok)
+	assert.Equal(t, failure.KindCanceled, kind)
+	assert.True(t, failure.IsNotFound(err))
+}
+
+func TestWrapContext_KeepsMetadata(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := failure.WithDetail(errors.New("foo"), "id", 1)
+	err = failure.WrapContext(ctx, err, "call")
+
+	v, ok := failure.Detail(err, "id")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestWithDeadlineCause(t *testing.T) {
+	budget := errors.New("db query budget exceeded")
+	query := errors.New("query interrupted")
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), time.Nanosecond, budget)
+	defer cancel()
+	<-ctx.Done()
+
+	err := failure.WithDeadlineCause(ctx, query)
+	assert.True(t, failure.IsTimeout(err))
+	assert.True(t, errors.Is(err, budget))
+	assert.True(t, errors.Is(err, query))
+	assert.Equal(t, "query interrupted: db query budget exceeded: "+failure.TimeoutMsg, err.Error())
+
+	err = failure.WrapContext(ctx, query, "load")
+	assert.True(t, errors.Is(err, budget))
+	assert.Equal(t, "load: query interrupted: db query budget exceeded: "+failure.TimeoutMsg, err.Error())
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	err = failure.WithDeadlineCause(ctx, query)
+	assert.Equal(t, "query interrupted: "+failure.TimeoutMsg, err.Error())
+
+	assert.Same(t, query, failure.WithDeadlineCause(context.Background(), query))
+	assert.Nil(t, failure.WithDeadlineCause(ctx, nil))
+}
+
+func TestFromContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, failure.FromContext(ctx))
+
+	cancel()
+	err := failure.FromContext(ctx)
+	assert.True(t, failure.IsCanceled(err))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, "context canceled: "+failure.CanceledMsg, err.Error())
+
+	budget := errors.New("query budget")
+	ctx, cancel = context.WithDeadlineCause(context.Background(), time.Now().Add(-time.Second), budget)
+	defer cancel()
+	err = failure.FromContext(ctx)
+	assert.True(t, failure.IsTimeout(err))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, errors.Is(err, budget))
+}
+
+func TestFromContextErr(t *testing.T) {
+	err := failure.FromContextErr(failure.Wrap(context.DeadlineExceeded, "query"))
+	assert.True(t, failure.IsTimeout(err))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	err = failure.FromContextErr(context.Canceled)
+	assert.True(t, failure.IsCanceled(err))
+
+	other := errors.New("boom")
+	assert.Equal(t, other, failure.FromContextErr(other))
+	assert.NoError(t, failure.FromContextErr(nil))
+}
@@ -0,0 +1,51 @@
+// Package datadogfail formats failures for Datadog Error Tracking.
+package datadogfail
+
+import (
+	"fmt"
+
+	"github.com/rsb/failure"
+)
+
+const (
+	KindAttr        = "error.kind"
+	MessageAttr     = "error.message"
+	StackAttr       = "error.stack"
+	CategoryAttr    = "error.category"
+	FingerprintAttr = "error.fingerprint"
+	SeverityAttr    = "error.severity"
+)
+
+// Attrs returns the attributes Datadog Error Tracking expects on a span or
+// log record, plus the failure category, fingerprint and severity. The kind
+// is the category for failures and the Go type for anything else, the stack
+// is only present when one was captured with failure.WithStack.
+//
+// With dd-trace-go the attributes become span tags:
+//
+//	for k, v := range datadogfail.Attrs(err) {
+//		span.SetTag(k, v)
+//	}
+func Attrs(err error) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+
+	attrs := map[string]interface{}{
+		KindAttr:        fmt.Sprintf("%T", err),
+		MessageAttr:     err.Error(),
+		FingerprintAttr: failure.Fingerprint(err),
+		SeverityAttr:    failure.SeverityOf(err).String(),
+	}
+
+	if c, ok := failure.Kind(err); ok {
+		attrs[KindAttr] = c.String()
+		attrs[CategoryAttr] = c.String()
+	}
+
+	if frames, ok := failure.StackTrace(err); ok {
+		attrs[StackAttr] = failure.FormatStack(frames)
+	}
+
+	return attrs
+}
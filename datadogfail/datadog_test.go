@@ -0,0 +1,31 @@
+package datadogfail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/rsb/failure/datadogfail"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrs(t *testing.T) {
+	err := failure.WithStack(failure.NotFound("user 1"))
+	attrs := datadogfail.Attrs(err)
+
+	assert.Equal(t, "not_found", attrs[datadogfail.KindAttr])
+	assert.Equal(t, "not_found", attrs[datadogfail.CategoryAttr])
+	assert.Equal(t, err.Error(), attrs[datadogfail.MessageAttr])
+	assert.Equal(t, failure.Fingerprint(err), attrs[datadogfail.FingerprintAttr])
+	assert.Equal(t, "warning", attrs[datadogfail.SeverityAttr])
+	assert.Contains(t, attrs[datadogfail.StackAttr], "datadogfail_test.TestAttrs")
+}
+
+func TestAttrs_Plain(t *testing.T) {
+	attrs := datadogfail.Attrs(errors.New("foo"))
+	assert.Equal(t, "*errors.errorString", attrs[datadogfail.KindAttr])
+	assert.NotContains(t, attrs, datadogfail.CategoryAttr)
+	assert.NotContains(t, attrs, datadogfail.StackAttr)
+
+	assert.Nil(t, datadogfail.Attrs(nil))
+}
@@ -0,0 +1,84 @@
+package failure
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetterMessage is a message that could not be processed, as read back
+// by ParseDeadLetter.
+type DeadLetterMessage struct {
+	// Err is the decoded failure, it keeps its message, category, details
+	// and attempts
+	Err         error
+	Attempts    int
+	Fingerprint string
+	FailedAt    time.Time
+	// Payload is the original message, byte for byte
+	Payload []byte
+}
+
+type deadLetterEnvelope struct {
+	Version     int          `json:"version"`
+	Failure     encodedError `json:"failure"`
+	Attempts    int          `json:"attempts,omitempty"`
+	Fingerprint string       `json:"fingerprint"`
+	FailedAt    time.Time    `json:"failed_at"`
+	Payload     []byte       `json:"payload"`
+}
+
+// DeadLetter packages e, with its category, details, attempts and
+// fingerprint, together with the original payload of the message into a
+// stable JSON envelope for publishing to a dead letter queue. The envelope
+// follows the rules of EncodingVersion, ParseDeadLetter reads it back.
+func DeadLetter(e error, payload []byte) ([]byte, error) {
+	if e == nil {
+		return nil, InvalidParam("can not dead letter a nil error")
+	}
+
+	env := deadLetterEnvelope{
+		Version:     EncodingVersion,
+		Failure:     encodeError(e),
+		Fingerprint: Fingerprint(e),
+		FailedAt:    time.Now().UTC(),
+		Payload:     payload,
+	}
+	if n, ok := Attempts(e); ok {
+		env.Attempts = n
+	}
+
+	out, err := json.Marshal(env)
+	if err != nil {
+		return nil, Wrap(err, "json.Marshal failed")
+	}
+
+	return out, nil
+}
+
+// ParseDeadLetter reads an envelope written by DeadLetter, for reprocessing
+// dead lettered messages.
+func ParseDeadLetter(data []byte) (*DeadLetterMessage, error) {
+	var env struct {
+		deadLetterEnvelope
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, Wrap(err, "json.Unmarshal failed")
+	}
+	if err := checkVersion(env.Version); err != nil {
+		return nil, err
+	}
+
+	e := env.Failure.decode()
+	if env.Attempts > 0 {
+		e = annotate(e, attemptsKey{}, env.Attempts)
+	}
+
+	return &DeadLetterMessage{
+		Err:         e,
+		Attempts:    env.Attempts,
+		Fingerprint: env.Fingerprint,
+		FailedAt:    env.FailedAt,
+		Payload:     env.Payload,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package failure_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetter(t *testing.T) {
+	payload := []byte(`{"order_id":7}`)
+	err := failure.Retry(context.Background(), failure.RetryPolicy{Attempts: 3, Backoff: noWait}, func(context.Context) error {
+		return failure.WithDetail(failure.Timeout("charge"), "order_id", 7)
+	})
+
+	data, e := failure.DeadLetter(err, payload)
+	require.NoError(t, e)
+
+	msg, e := failure.ParseDeadLetter(data)
+	require.NoError(t, e)
+	assert.Equal(t, payload, msg.Payload)
+	assert.Equal(t, 3, msg.Attempts)
+	assert.Equal(t, failure.Fingerprint(err), msg.Fingerprint)
+	assert.WithinDuration(t, time.Now(), msg.FailedAt, time.Minute)
+
+	assert.True(t, failure.IsTimeout(msg.Err))
+	assert.Equal(t, err.Error(), msg.Err.Error())
+	attempts, _ := failure.Attempts(msg.Err)
+	assert.Equal(t, 3, attempts)
+	id, _ := failure.Detail(msg.Err, "order_id")
+	assert.Equal(t, float64(7), id)
+
+	_, e = failure.DeadLetter(nil, payload)
+	assert.True(t, failure.IsInvalidParam(e))
+
+	_, e = failure.ParseDeadLetter([]byte(`{"version":0}`))
+	assert.True(t, failure.IsInvalidParam(e))
+}
@@ -0,0 +1,162 @@
+package failure
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Deduper sits in front of a logger and suppresses identical failures,
+// failures with the same Fingerprint, after the first few occurrences within
+// a window. Each fingerprint has its own window, starting with its first
+// occurrence. When a window closes a single summary failure is logged in
+// place of everything that was suppressed, protecting log volume without
+// losing the signal. It is safe for concurrent use, call Close when done
+// with it.
+type Deduper struct {
+	mutex   sync.Mutex
+	limit   int
+	window  time.Duration
+	log     func(error)
+	closed  bool
+	windows map[string]*dedupWindow
+}
+
+type dedupWindow struct {
+	first      error
+	start      time.Time
+	seen       int
+	suppressed int
+	// timer closes the window once it has lasted the window length
+	timer *time.Timer
+}
+
+// NewDeduper creates a Deduper that lets limit occurrences of each
+// fingerprint through to log per window.
+func NewDeduper(limit int, window time.Duration, log func(error)) *Deduper {
+	return &Deduper{
+		limit:   limit,
+		window:  window,
+		log:     log,
+		windows: map[string]*dedupWindow{},
+	}
+}
+
+// Log forwards e to the logger unless its fingerprint already reached the
+// limit in the current window. nil errors are ignored.
+func (d *Deduper) Log(e error) {
+	d.LogAt(e, time.Now())
+}
+
+// LogAt is Log with an explicit time for the occurrence. A window that
+// ended before at is closed first, the timer closing windows that see no
+// further occurrences runs on the wall clock.
+func (d *Deduper) LogAt(e error, at time.Time) {
+	if e == nil {
+		return
+	}
+
+	fp := Fingerprint(e)
+
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		d.log(e)
+		return
+	}
+
+	var out []error
+	w, ok := d.windows[fp]
+	if ok && at.Sub(w.start) >= d.window {
+		if s := d.closeWindow(fp, w); s != nil {
+			out = append(out, s)
+		}
+		ok = false
+	}
+	if !ok {
+		w = &dedupWindow{first: e, start: at}
+		w.timer = time.AfterFunc(d.window, func() { d.expire(fp, w) })
+		d.windows[fp] = w
+	}
+
+	w.seen++
+	if w.seen <= d.limit {
+		out = append(out, e)
+	} else {
+		w.suppressed++
+	}
+	d.mutex.Unlock()
+
+	for _, x := range out {
+		d.log(x)
+	}
+}
+
+// Flush closes every open window, logging a summary for each one that
+// suppressed failures.
+func (d *Deduper) Flush() {
+	d.mutex.Lock()
+	var out []error
+	for fp, w := range d.windows {
+		if s := d.closeWindow(fp, w); s != nil {
+			out = append(out, s)
+		}
+	}
+	d.mutex.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Error() < out[j].Error() })
+	for _, x := range out {
+		d.log(x)
+	}
+}
+
+// Close flushes the open windows and stops their timers, failures logged
+// afterwards are forwarded as they are.
+func (d *Deduper) Close() {
+	d.Flush()
+
+	d.mutex.Lock()
+	d.closed = true
+	d.mutex.Unlock()
+}
+
+// expire closes w when its timer fires, unless it was closed already
+func (d *Deduper) expire(fp string, w *dedupWindow) {
+	d.mutex.Lock()
+	if d.windows[fp] != w {
+		d.mutex.Unlock()
+		return
+	}
+	s := d.closeWindow(fp, w)
+	d.mutex.Unlock()
+
+	if s != nil {
+		d.log(s)
+	}
+}
+
+// closeWindow forgets w and returns its summary, the mutex must be held
+func (d *Deduper) closeWindow(fp string, w *dedupWindow) error {
+	w.timer.Stop()
+	delete(d.windows, fp)
+
+	return summarize(fp, w)
+}
+
+func summarize(fp string, w *dedupWindow) error {
+	if w.suppressed == 0 {
+		return nil
+	}
+
+	return Wrap(w.first, "suppressed %s additional occurrences of %s", formatCount(w.suppressed), fp)
+}
+
+// formatCount renders n with thousands separators
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
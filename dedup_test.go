@@ -0,0 +1,108 @@
+package failure_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduper(t *testing.T) {
+	var logged []error
+	d := failure.NewDeduper(2, time.Minute, func(e error) {
+		logged = append(logged, e)
+	})
+
+	start := time.Date(2022, 5, 26, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4214; i++ {
+		d.LogAt(failure.Timeout("call %d took too long", i), start)
+	}
+	d.LogAt(failure.NotFound("user 1"), start)
+	d.LogAt(nil, start)
+	require.Len(t, logged, 3)
+
+	fp := failure.Fingerprint(failure.Timeout("call 1 took too long"))
+
+	// the next occurrence after the window closes triggers the summary
+	d.LogAt(failure.Timeout("call 1 took too long"), start.Add(2*time.Minute))
+	require.Len(t, logged, 5)
+
+	summary := logged[3]
+	assert.True(t, failure.IsTimeout(summary))
+	assert.Contains(t, summary.Error(), "suppressed 4,212 additional occurrences of "+fp)
+	assert.Contains(t, logged[4].Error(), "call 1 took too long")
+}
+
+func TestDeduper_Flush(t *testing.T) {
+	var logged []error
+	d := failure.NewDeduper(1, time.Hour, func(e error) {
+		logged = append(logged, e)
+	})
+
+	d.Log(failure.System("boom"))
+	d.Log(failure.System("boom"))
+	d.Log(failure.System("boom"))
+	d.Log(failure.Warn("careful"))
+	require.Len(t, logged, 2)
+
+	d.Flush()
+	require.Len(t, logged, 3)
+	assert.Contains(t, logged[2].Error(), "suppressed 2 additional occurrences")
+
+	d.Flush()
+	assert.Len(t, logged, 3)
+}
+
+func TestDeduper_WindowPerFingerprint(t *testing.T) {
+	var logged []error
+	d := failure.NewDeduper(1, time.Minute, func(e error) {
+		logged = append(logged, e)
+	})
+	defer d.Close()
+
+	start := time.Date(2022, 5, 26, 12, 0, 0, 0, time.UTC)
+	d.LogAt(failure.System("boom"), start)
+	d.LogAt(failure.Timeout("slow"), start.Add(30*time.Second))
+	d.LogAt(failure.System("boom"), start.Add(61*time.Second))
+	require.Len(t, logged, 3)
+
+	// the window of the timeout started 30s in, so it is over at 90s
+	d.LogAt(failure.Timeout("slow"), start.Add(91*time.Second))
+	require.Len(t, logged, 4)
+	assert.True(t, failure.IsTimeout(logged[3]))
+}
+
+func TestDeduper_Close(t *testing.T) {
+	var mutex sync.Mutex
+	var logged []error
+	d := failure.NewDeduper(1, 20*time.Millisecond, func(e error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		logged = append(logged, e)
+	})
+	count := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(logged)
+	}
+
+	d.Log(failure.System("boom"))
+	d.Log(failure.System("boom"))
+	require.Equal(t, 1, count())
+
+	// the summary is logged when the window ends, without waiting for
+	// another occurrence
+	assert.Eventually(t, func() bool { return count() == 2 }, time.Second, 5*time.Millisecond)
+
+	d.Log(failure.Warn("careful"))
+	d.Log(failure.Warn("careful"))
+	d.Close()
+	require.Equal(t, 4, count())
+	assert.Contains(t, logged[3].Error(), "suppressed 1 additional occurrences")
+
+	d.Log(failure.Warn("careful"))
+	assert.Equal(t, 5, count())
+}
@@ -0,0 +1,40 @@
+package failure
+
+// detailKey is the annotation key of a detail, keeping details apart from
+// the metadata this package stores itself.
+type detailKey string
+
+// WithDetail returns e annotated with a key/value detail, such as the id of
+// the resource involved. Details are reported and logged alongside the
+// failure but are not part of its message. e itself is left untouched.
+func WithDetail(e error, key string, value interface{}) error {
+	return annotate(e, detailKey(key), value)
+}
+
+// Detail returns the value of the outermost detail named key
+func Detail(e error, key string) (interface{}, bool) {
+	return lookup(e, detailKey(key))
+}
+
+// Details returns every detail found in the chain of e. When a key was set
+// more than once the outermost value wins. A new map is returned on every
+// call, so callers are free to modify it.
+func Details(e error) map[string]interface{} {
+	out := map[string]interface{}{}
+	for e != nil {
+		switch x := e.(type) {
+		case *annotation:
+			if k, ok := x.key.(detailKey); ok {
+				if _, exists := out[string(k)]; !exists {
+					out[string(k)] = x.value
+				}
+			}
+		case *Multi:
+			return out
+		}
+
+		e = next(e)
+	}
+
+	return out
+}
@@ -0,0 +1,43 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDetail(t *testing.T) {
+	base := failure.NotFound("user")
+	err := failure.WithDetail(base, "user_id", 42)
+	err = failure.Wrap(err, "load profile")
+	err = failure.WithDetail(err, "user_id", 7)
+	err = failure.WithDetail(err, "region", "us-east-1")
+
+	assert.True(t, failure.IsNotFound(err))
+	assert.Equal(t, "load profile: user: "+failure.NotFoundMsg, err.Error())
+
+	v, ok := failure.Detail(err, "user_id")
+	assert.True(t, ok)
+	assert.Equal(t, 7, v)
+
+	_, ok = failure.Detail(base, "user_id")
+	assert.False(t, ok)
+
+	details := failure.Details(err)
+	assert.Equal(t, map[string]interface{}{"user_id": 7, "region": "us-east-1"}, details)
+
+	details["user_id"] = 1
+	assert.Equal(t, 7, failure.Details(err)["user_id"])
+
+	assert.Empty(t, failure.Details(errors.New("foo")))
+	assert.Nil(t, failure.WithDetail(nil, "foo", "bar"))
+}
+
+func TestDetails_Multi(t *testing.T) {
+	member := failure.WithDetail(failure.System("foo"), "id", 1)
+	err := failure.Multiple([]error{member})
+
+	assert.Empty(t, failure.Details(err))
+}
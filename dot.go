@@ -0,0 +1,107 @@
+package failure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dotLabelMax bounds the length of a node label in ToDOT
+const dotLabelMax = 80
+
+// ToDOT renders the structure of e as a Graphviz graph, one node per layer
+// and an edge to everything it wraps. Members of a Multi and the errors of a
+// reclassified failure branch out, which makes the shape of large fan-out
+// failures visible:
+//
+//	os.WriteFile("failure.dot", []byte(failure.ToDOT(err)), 0o644)
+//	// dot -Tsvg failure.dot > failure.svg
+func ToDOT(e error) string {
+	g := dotGraph{}
+	g.b.WriteString("digraph failure {\n\tnode [shape=box];\n")
+	if e != nil {
+		g.node(e)
+	}
+	g.b.WriteString("}\n")
+
+	return g.b.String()
+}
+
+type dotGraph struct {
+	b    strings.Builder
+	next int
+}
+
+// node writes e and everything it wraps, returning the id of e
+func (g *dotGraph) node(e error) string {
+	// annotations carry metadata, not structure
+	for unwrapped := false; !unwrapped; {
+		switch x := e.(type) {
+		case *annotation:
+			e = x.err
+		case *netTimeout:
+			e = x.err
+		default:
+			unwrapped = true
+		}
+	}
+
+	id := fmt.Sprintf("n%d", g.next)
+	g.next++
+
+	var label, shape string
+	var children []error
+	switch x := e.(type) {
+	case Category:
+		label, shape = x.String(), "ellipse"
+	case *classified:
+		label = "classified as " + x.kind.String()
+		children = []error{x.kind, x.err}
+		if x.cause != nil {
+			children = append(children, x.cause)
+		}
+	case *RestAPI:
+		label = fmt.Sprintf("status %d: %s", x.StatusCode, x.Msg)
+		children = []error{x.Err}
+	case *redacted:
+		label = x.Error()
+	case *Multi:
+		label, shape = fmt.Sprintf("Multi (%d)", len(x.Failures)), "box3d"
+		children = x.Failures
+	case interface{ Unwrap() []error }:
+		children = x.Unwrap()
+		label, shape = fmt.Sprintf("%T (%d)", e, len(children)), "box3d"
+	default:
+		inner := next(e)
+		label = layerMessage(e, inner)
+		if inner != nil {
+			children = []error{inner}
+		}
+	}
+
+	if shape != "" {
+		fmt.Fprintf(&g.b, "\t%s [label=%s, shape=%s];\n", id, dotQuote(label), shape)
+	} else {
+		fmt.Fprintf(&g.b, "\t%s [label=%s];\n", id, dotQuote(label))
+	}
+
+	for _, c := range children {
+		if c == nil {
+			continue
+		}
+		fmt.Fprintf(&g.b, "\t%s -> %s;\n", id, g.node(c))
+	}
+
+	return id
+}
+
+func dotQuote(s string) string {
+	if r := []rune(s); len(r) > dotLabelMax {
+		s = string(r[:dotLabelMax-3]) + "..."
+	}
+
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
@@ -0,0 +1,40 @@
+package failure_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDOT(t *testing.T) {
+	err := failure.Append(nil,
+		failure.WithDetail(failure.Timeout("db"), "table", "users"),
+		failure.To(failure.KindSystem, errors.New(`disk "a" full`), "write"),
+	)
+
+	assert.Equal(t, `digraph failure {
+	node [shape=box];
+	n0 [label="Multi (2)", shape=box3d];
+	n1 [label="db"];
+	n2 [label="timeout", shape=ellipse];
+	n1 -> n2;
+	n0 -> n1;
+	n3 [label="write"];
+	n4 [label="classified as system"];
+	n5 [label="system", shape=ellipse];
+	n4 -> n5;
+	n6 [label="disk \"a\" full"];
+	n4 -> n6;
+	n3 -> n4;
+	n0 -> n3;
+}
+`, failure.ToDOT(err))
+
+	assert.Equal(t, "digraph failure {\n\tnode [shape=box];\n}\n", failure.ToDOT(nil))
+
+	long := failure.ToDOT(errors.New(strings.Repeat("x", 200)))
+	assert.Contains(t, long, strings.Repeat("x", 77)+`..."`)
+}
@@ -0,0 +1,326 @@
+package failure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// EncodingVersion is the schema version written with every encoded failure.
+//
+// The schema only ever evolves by adding fields, so decoding follows two
+// rules that keep services on different versions of this package talking
+// during rolling deploys:
+//
+//   - payloads without a version predate versioning and are read as version 1
+//   - payloads from a newer version are read best effort, the fields this
+//     version knows keep their meaning and the others are ignored
+//
+// Only a version below 1 is rejected.
+const EncodingVersion = 1
+
+// encodedError is the structured encoding of a single failure, it keeps
+// what is needed to inspect the failure again once decoded.
+type encodedError struct {
+	Version  int                    `json:"version,omitempty"`
+	Message  string                 `json:"message"`
+	Category string                 `json:"category,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Chain    []string               `json:"chain,omitempty"`
+	Rest     *encodedRest           `json:"rest,omitempty"`
+	Catalog  *encodedCatalog        `json:"catalog,omitempty"`
+}
+
+// encodedRest is the encoding of a RestAPI found in the chain
+type encodedRest struct {
+	Status int               `json:"status"`
+	Msg    string            `json:"msg,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// encodedCatalog is the encoding of a Catalog, on its own or found in the
+// chain
+type encodedCatalog struct {
+	Key    string        `json:"key"`
+	Status int           `json:"status"`
+	Errors fieldsByGroup `json:"errors"`
+}
+
+func encodeError(e error) encodedError {
+	out := encodedError{Message: e.Error()}
+	if c, ok := Kind(e); ok {
+		out.Category = c.String()
+	}
+	if details := Details(e); len(details) > 0 {
+		out.Details = details
+	}
+	if chain := MessageChain(e); len(chain) > 1 {
+		out.Chain = chain
+	}
+
+	var r *RestAPI
+	if errors.As(e, &r) {
+		out.Rest = &encodedRest{Status: r.StatusCode, Msg: r.Msg, Fields: r.Fields}
+	}
+
+	var c *Catalog
+	if errors.As(e, &c) {
+		out.Catalog = &encodedCatalog{Key: c.Key, Status: c.Status, Errors: c.fieldsByGroup()}
+	}
+
+	return out
+}
+
+// decode rebuilds the failure, it reports the original message and unwraps
+// to its category, RestAPI and Catalog so errors.Is, errors.As and the IsX
+// functions still apply.
+func (x encodedError) decode() error {
+	d := &decoded{msg: x.Message, chain: x.Chain, kind: Category(x.Category)}
+
+	if x.Rest != nil {
+		var inner error = d.kind
+		if d.kind == "" {
+			inner = errors.New(x.Rest.Msg)
+		}
+		d.rest = &RestAPI{StatusCode: x.Rest.Status, Msg: x.Rest.Msg, Fields: x.Rest.Fields, Err: inner}
+	}
+
+	if x.Catalog != nil {
+		d.catalog = new(Catalog)
+		x.Catalog.decode(d.catalog)
+	}
+
+	var e error = d
+
+	keys := make([]string, 0, len(x.Details))
+	for k := range x.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e = WithDetail(e, k, x.Details[k])
+	}
+
+	return e
+}
+
+// decode replaces the key, status and fields of c with the encoded ones
+func (x encodedCatalog) decode(c *Catalog) {
+	c.Key = x.Key
+	c.Status = x.Status
+	if c.Status == 0 {
+		c.Status = http.StatusUnprocessableEntity
+	}
+	c.Groups = map[string]*FieldGroup{}
+	c.order = c.order[:0]
+	for _, g := range x.Errors {
+		for _, f := range g.fields {
+			c.AddField(g.name, f.Name, "%s", f.Msg)
+		}
+	}
+}
+
+// decoded is a failure read back from its structured encoding
+type decoded struct {
+	msg     string
+	chain   []string
+	kind    Category
+	rest    *RestAPI
+	catalog *Catalog
+}
+
+func (d *decoded) Error() string {
+	return d.msg
+}
+
+func (d *decoded) Unwrap() []error {
+	var out []error
+	if d.kind != "" {
+		out = append(out, d.kind)
+	}
+	if d.rest != nil {
+		out = append(out, d.rest)
+	}
+	if d.catalog != nil {
+		out = append(out, d.catalog)
+	}
+
+	return out
+}
+
+// MessageChain returns the message added by every layer of e, outermost
+// first, ending with the message of its category or of the original error.
+// A failure read back with Decode returns the chain it had when encoded.
+//
+//	failure.MessageChain(failure.Wrap(failure.NotFound("user 7"), "load profile"))
+//	// []string{"load profile", "user 7", "not found failure"}
+func MessageChain(e error) []string {
+	var out []string
+	for e != nil {
+		switch x := e.(type) {
+		case *annotation:
+			e = x.err
+			continue
+		case *netTimeout:
+			e = x.err
+			continue
+		case *redacted:
+			return append(out, x.Error())
+		case *decoded:
+			if len(x.chain) > 0 {
+				return append(out, x.chain...)
+			}
+			return append(out, x.msg)
+		case *classified:
+			e = x.err
+			continue
+		case *RestAPI:
+			if x.Msg != "" {
+				out = append(out, x.Msg)
+			}
+			e = x.Err
+			continue
+		case Category, *Multi, *Catalog, interface{ Unwrap() []error }:
+			return append(out, e.Error())
+		}
+
+		inner := next(e)
+		out = append(out, layerMessage(e, inner))
+		e = inner
+	}
+
+	return out
+}
+
+type encodedMulti struct {
+	Version  int            `json:"version"`
+	Failures []encodedError `json:"failures"`
+}
+
+// checkVersion applies the decoding rules of EncodingVersion, a missing
+// version is accepted as 1.
+func checkVersion(v *int) error {
+	if v != nil && *v < 1 {
+		return InvalidParam("unsupported encoding version (%d)", *v)
+	}
+	return nil
+}
+
+// Encode returns the versioned JSON encoding of e, see EncodingVersion, for
+// propagating failures across service boundaries such as queues and
+// internal APIs. The encoding carries the message, its chain, the
+// category, the details and the data of a RestAPI or Catalog found in the
+// chain. A Multi is encoded with its failures.
+func Encode(e error) ([]byte, error) {
+	if m, ok := e.(*Multi); ok {
+		return m.MarshalJSON()
+	}
+	if e == nil {
+		return nil, InvalidParam("can not encode a nil error")
+	}
+
+	x := encodeError(e)
+	x.Version = EncodingVersion
+	return json.Marshal(x)
+}
+
+// Decode rebuilds a failure from the encoding produced by Encode or by
+// Multi.MarshalJSON. The decoded failure keeps its message, category and
+// details so errors.Is, errors.As and the IsX functions still apply,
+// GetInvalidFields and IsBadRequest included.
+func Decode(data []byte) (error, error) {
+	var probe struct {
+		Version  *int            `json:"version"`
+		Failures json.RawMessage `json:"failures"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, Wrap(err, "json.Unmarshal failed")
+	}
+	if err := checkVersion(probe.Version); err != nil {
+		return nil, err
+	}
+
+	if probe.Failures != nil {
+		m := new(Multi)
+		if err := m.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+
+	var x encodedError
+	if err := json.Unmarshal(data, &x); err != nil {
+		return nil, Wrap(err, "json.Unmarshal failed")
+	}
+
+	return x.decode(), nil
+}
+
+// MarshalJSON encodes every failure, nested Multi are flattened, with its
+// message, category and details, so batch endpoints can return them all in
+// a structured body:
+//
+//	{"version":1,"failures":[{"message":"row 3: not found failure","category":"not_found"}]}
+//
+// MultiFromJSON reads it back.
+func (e *Multi) MarshalJSON() ([]byte, error) {
+	out := encodedMulti{Version: EncodingVersion, Failures: []encodedError{}}
+	if e != nil {
+		flat := new(Multi)
+		flatten(e, flat)
+		for _, x := range flat.Failures {
+			if x != nil {
+				out.Failures = append(out.Failures, encodeError(x))
+			}
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+// MultiFromJSON decodes the failures encoded by Multi.MarshalJSON, a bare
+// array of encoded failures is accepted too. See UnmarshalJSON.
+func MultiFromJSON(data []byte) (*Multi, error) {
+	m := new(Multi)
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var in []encodedError
+		if err := json.Unmarshal(trimmed, &in); err != nil {
+			return nil, Wrap(err, "json.Unmarshal failed")
+		}
+		for _, x := range in {
+			m.Failures = append(m.Failures, x.decode())
+		}
+		return m, nil
+	}
+
+	if err := m.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalJSON replaces the failures of e with the decoded ones. Decoded
+// failures keep their message, category and details but not their original
+// types.
+func (e *Multi) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Version  *int           `json:"version"`
+		Failures []encodedError `json:"failures"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return Wrap(err, "json.Unmarshal failed")
+	}
+	if err := checkVersion(in.Version); err != nil {
+		return err
+	}
+
+	e.Failures = make([]error, len(in.Failures))
+	for i, x := range in.Failures {
+		e.Failures[i] = x.decode()
+	}
+
+	return nil
+}
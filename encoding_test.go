@@ -0,0 +1,154 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulti_JSON(t *testing.T) {
+	original := failure.Append(nil,
+		failure.WithDetail(failure.NotFound("user 1"), "user_id", 1),
+		failure.Append(nil, failure.Timeout("db")),
+		errors.New("plain"),
+	)
+
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	var loaded failure.Multi
+	require.NoError(t, json.Unmarshal(data, &loaded))
+	require.Len(t, loaded.Failures, 3)
+
+	assert.Equal(t, original.Failures[0].Error(), loaded.Failures[0].Error())
+	assert.True(t, failure.IsNotFound(loaded.Failures[0]))
+	assert.True(t, errors.Is(&loaded, failure.KindTimeout))
+
+	id, ok := failure.Detail(loaded.Failures[0], "user_id")
+	require.True(t, ok)
+	assert.Equal(t, float64(1), id)
+
+	_, ok = failure.Kind(loaded.Failures[2])
+	assert.False(t, ok)
+	assert.Equal(t, "plain", loaded.Failures[2].Error())
+
+	data, err = json.Marshal(&failure.Multi{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"failures":[]}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"failures":1}`), &loaded))
+}
+
+func TestEncode(t *testing.T) {
+	original := failure.WithDetail(failure.Timeout("db"), "table", "users")
+
+	data, err := failure.Encode(original)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"version":1`)
+
+	decoded, err := failure.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, original.Error(), decoded.Error())
+	assert.True(t, failure.IsTimeout(decoded))
+	table, _ := failure.Detail(decoded, "table")
+	assert.Equal(t, "users", table)
+
+	data, err = failure.Encode(failure.Append(nil, failure.NotFound("a"), failure.System("b")))
+	require.NoError(t, err)
+	decoded, err = failure.Decode(data)
+	require.NoError(t, err)
+	assert.True(t, failure.IsMultiple(decoded))
+	assert.True(t, failure.IsNotFound(decoded))
+
+	_, err = failure.Encode(nil)
+	assert.True(t, failure.IsInvalidParam(err))
+}
+
+func TestDecode_Versions(t *testing.T) {
+	// payloads written before versioning
+	decoded, err := failure.Decode([]byte(`{"message":"user 1: not found failure","category":"not_found"}`))
+	require.NoError(t, err)
+	assert.True(t, failure.IsNotFound(decoded))
+
+	// payloads from a newer version keep the fields known to this one
+	decoded, err = failure.Decode([]byte(`{"version":7,"message":"boom","category":"system","trace_id":"abc"}`))
+	require.NoError(t, err)
+	assert.True(t, failure.IsSystem(decoded))
+	assert.Equal(t, "boom", decoded.Error())
+
+	var multi failure.Multi
+	require.NoError(t, json.Unmarshal([]byte(`{"version":2,"failures":[{"message":"x","priority":1}]}`), &multi))
+	assert.Len(t, multi.Failures, 1)
+
+	_, err = failure.Decode([]byte(`{"version":0,"message":"boom"}`))
+	assert.True(t, failure.IsInvalidParam(err))
+	assert.Error(t, json.Unmarshal([]byte(`{"version":-1,"failures":[]}`), &multi))
+
+	_, err = failure.Decode([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestEncode_Propagation(t *testing.T) {
+	original := failure.Wrap(failure.NotFound("user 7"), "load profile")
+	assert.Equal(t, []string{"load profile", "user 7", failure.NotFoundMsg}, failure.MessageChain(original))
+
+	data, err := failure.Encode(original)
+	require.NoError(t, err)
+	decoded, err := failure.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, original.Error(), decoded.Error())
+	assert.True(t, failure.IsNotFound(decoded))
+	assert.Equal(t, failure.MessageChain(original), failure.MessageChain(decoded))
+
+	fields := map[string]string{"email": "is required"}
+	data, err = failure.Encode(failure.Wrap(failure.InvalidFields(fields, "invalid user"), "create"))
+	require.NoError(t, err)
+	decoded, err = failure.Decode(data)
+	require.NoError(t, err)
+	assert.True(t, failure.IsInvalidFields(decoded))
+	got, ok := failure.GetInvalidFields(decoded)
+	require.True(t, ok)
+	assert.Equal(t, fields, got)
+	assert.Equal(t, http.StatusUnprocessableEntity, failure.HTTPStatus(decoded))
+
+	c := failure.NewCatalog("create_user", 0)
+	c.AddField("body", "email", "is required")
+	data, err = failure.Encode(c)
+	require.NoError(t, err)
+	decoded, err = failure.Decode(data)
+	require.NoError(t, err)
+	assert.True(t, errors.Is(decoded, failure.KindInvalidAPIFields))
+
+	var loaded *failure.Catalog
+	require.True(t, errors.As(decoded, &loaded))
+	assert.Equal(t, "create_user", loaded.Key)
+	assert.Equal(t, c.AllFailures(), loaded.AllFailures())
+}
+
+func TestMultiFromJSON(t *testing.T) {
+	m := failure.Append(nil, failure.NotFound("row 3"), failure.Validation("row 5"))
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	back, err := failure.MultiFromJSON(data)
+	require.NoError(t, err)
+	require.Len(t, back.Failures, 2)
+	assert.True(t, failure.IsNotFound(back.Failures[0]))
+	assert.True(t, failure.IsValidation(back.Failures[1]))
+	assert.Equal(t, m.Failures[1].Error(), back.Failures[1].Error())
+
+	back, err = failure.MultiFromJSON([]byte(` [{"message":"row 3: not found failure","category":"not_found"}]`))
+	require.NoError(t, err)
+	require.Len(t, back.Failures, 1)
+	assert.True(t, failure.IsNotFound(back.Failures[0]))
+
+	_, err = failure.MultiFromJSON([]byte(`[{"message":1}]`))
+	assert.Error(t, err)
+	_, err = failure.MultiFromJSON([]byte(`{"version":0,"failures":[]}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package failure
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Enricher adds context to a failure before it is reported, typically as
+// details: the host, pod and region of the process or request scoped values
+// read from ctx. It returns the enriched failure.
+type Enricher func(ctx context.Context, err error) error
+
+var enriching = struct {
+	sync.RWMutex
+	seq       int
+	enrichers map[int]Enricher
+	order     []int
+}{
+	enrichers: map[int]Enricher{},
+}
+
+// AddEnricher adds fn to the enrichers run by Enrich, and so by Report and
+// the middleware, in the order they were added. The returned function
+// removes it again.
+func AddEnricher(fn Enricher) func() {
+	enriching.Lock()
+	defer enriching.Unlock()
+
+	enriching.seq++
+	id := enriching.seq
+	enriching.enrichers[id] = fn
+	enriching.order = append(enriching.order, id)
+
+	return func() {
+		enriching.Lock()
+		defer enriching.Unlock()
+
+		delete(enriching.enrichers, id)
+		for i, x := range enriching.order {
+			if x == id {
+				enriching.order = append(enriching.order[:i:i], enriching.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Enrich runs every enricher on err. An enricher returning nil leaves the
+// failure as it was. nil errors are returned as is.
+func Enrich(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	enriching.RLock()
+	fns := make([]Enricher, 0, len(enriching.order))
+	for _, id := range enriching.order {
+		fns = append(fns, enriching.enrichers[id])
+	}
+	enriching.RUnlock()
+
+	for _, fn := range fns {
+		if out := fn(ctx, err); out != nil {
+			err = out
+		}
+	}
+
+	return err
+}
+
+// DetailsEnricher returns an Enricher adding the same details to every
+// failure, for values fixed for the life of the process:
+//
+//	host, _ := os.Hostname()
+//	failure.AddEnricher(failure.DetailsEnricher(map[string]interface{}{
+//		"host":   host,
+//		"pod":    os.Getenv("POD_NAME"),
+//		"region": os.Getenv("REGION"),
+//	}))
+func DetailsEnricher(details map[string]interface{}) Enricher {
+	keys := make([]string, 0, len(details))
+	values := make(map[string]interface{}, len(details))
+	for k, v := range details {
+		keys = append(keys, k)
+		values[k] = v
+	}
+	sort.Strings(keys)
+
+	return func(_ context.Context, err error) error {
+		for _, k := range keys {
+			err = WithDetail(err, k, values[k])
+		}
+		return err
+	}
+}
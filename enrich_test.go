@@ -0,0 +1,47 @@
+package failure_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type requestIDKey struct{}
+
+func TestAddEnricher(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	removeHost := failure.AddEnricher(failure.DetailsEnricher(map[string]interface{}{
+		"host":   "web-1",
+		"region": "us-east-1",
+	}))
+	removeRequest := failure.AddEnricher(func(ctx context.Context, err error) error {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return failure.WithDetail(err, "request_id", id)
+		}
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+	failure.Report(ctx, failure.System("boom"))
+
+	removeHost()
+	failure.Report(context.Background(), failure.System("boom"))
+	removeRequest()
+
+	reported := rec.reported()
+	require.Len(t, reported, 2)
+	assert.Equal(t, map[string]interface{}{
+		"host":       "web-1",
+		"region":     "us-east-1",
+		"request_id": "abc",
+	}, failure.Details(reported[0]))
+	assert.Empty(t, failure.Details(reported[1]))
+	assert.True(t, failure.IsSystem(reported[1]))
+
+	assert.Nil(t, failure.Enrich(ctx, nil))
+}
@@ -1,10 +1,20 @@
 // Package failure implements an opaque error pattern based several of the most
 // common  types of errors that occur when developing microservices.
+//
+// Metadata such as details, severity, retryability and stacks is attached
+// copy-on-write: WithDetail, WithSeverity, MarkRetryable and the other With
+// and Mark functions return a new error wrapping the one given and never
+// modify it. A failure, including a package level sentinel, can therefore be
+// enriched and read from any number of goroutines without synchronization.
+// Multi is the exception, Append and Prefix modify it in place.
 package failure
 
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -31,275 +41,506 @@ const (
 	WarnMsg               = "warning"
 	NoChangeMsg           = "no change has occurred"
 	InvalidStateMsg       = "invalid state"
+	CanceledMsg           = "canceled failure"
+	OverloadedMsg         = "service is overloaded"
+	ExpiredMsg            = "expired failure"
+	RateLimitedMsg        = "rate limit exceeded"
+	ConflictMsg           = "conflict failure"
+	NotImplementedMsg     = "not implemented"
+	UnavailableMsg        = "service unavailable"
+	ResourceExhaustedMsg  = "resource exhausted"
+	PayloadTooLargeMsg    = "payload too large"
+)
+
+// New creates a failure of the given category, for code that picks the
+// category at runtime such as validation tables or config driven policies.
+// New(KindNotFound, "user %d", id) is the same as NotFound("user %d", id).
+func New(cat Category, format string, a ...interface{}) error {
+	return Wrap(cat, format, a...)
+}
+
+// To classifies e as the given category and wraps it with the message, e
+// stays reachable with errors.Is and errors.As. nil is returned when e is
+// nil.
+func To(cat Category, e error, format string, a ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+
+	return classify(e, cat, format, a...)
+}
+
+// ExpirySubject is what expired, it decides how an Expired failure is
+// answered: an expired token asks the client to authenticate again while
+// anything else is gone for good.
+type ExpirySubject string
 
-	systemErr             = err(SystemMsg)
-	serverErr             = err(ServerMsg)
-	shutdownErr           = err(ShutdownMsg)
-	configErr             = err(ConfigMsg)
-	notFoundErr           = err(NotFoundMsg)
-	notAuthorizedErr      = err(NotAuthorizedMsg)
-	notAuthenticatedErr   = err(NotAuthenticatedMsg)
-	forbiddenErr          = err(ForbiddenMsg)
-	validationErr         = err(ValidationMsg)
-	invalidParamErr       = err(InvalidParamMsg)
-	deferErr              = err(DeferMsg)
-	ignoreErr             = err(IgnoreMsg)
-	timeoutErr            = err(TimeoutMsg)
-	startupErr            = err(StartupMsg)
-	panicErr              = err(PanicMsg)
-	badRequestErr         = err(BadRequestMsg)
-	invalidAPIFieldsErr   = err(InvalidAPIFieldsMsg)
-	missingFromContextErr = err(MissingFromContextMsg)
-	alreadyExistsErr      = err(AlreadyExistsMsg)
-	outOfRangeErr         = err(OutOfRangeMsg)
-	warnErr               = err(WarnMsg)
-	noChangeErr           = err(NoChangeMsg)
-	invalidStateErr       = err(InvalidStateMsg)
+const (
+	ExpiredToken      ExpirySubject = "token"
+	ExpiredLicense    ExpirySubject = "license"
+	ExpiredLink       ExpirySubject = "link"
+	ExpiredCacheEntry ExpirySubject = "cache_entry"
 )
 
-type err string
+// Expired is used to signal that something was valid once but is not
+// anymore, which is neither NotAuthenticated nor NotFound.
+func Expired(format string, a ...interface{}) error {
+	return Wrap(KindExpired, format, a...)
+}
+
+// NewExpired is Expired carrying what expired and when, see ExpiredSubject
+// and ExpiredAt. Responses use 401 for an expired token and 410 otherwise.
+func NewExpired(subject ExpirySubject, at time.Time, format string, a ...interface{}) error {
+	err := annotate(Expired(format, a...), expirySubjectKey{}, subject)
+	return annotate(err, expiredAtKey{}, at)
+}
+
+func IsExpired(e error) bool {
+	return errors.Is(e, KindExpired)
+}
+
+func ToExpired(e error, format string, a ...interface{}) error {
+	cause := fromError(e, Expired)
+	return Wrap(cause, format, a...)
+}
+
+type expirySubjectKey struct{}
+
+type expiredAtKey struct{}
+
+// ExpiredSubject returns what expired according to an Expired failure
+func ExpiredSubject(e error) (ExpirySubject, bool) {
+	v, ok := lookup(e, expirySubjectKey{})
+	if !ok {
+		return "", false
+	}
+	return v.(ExpirySubject), true
+}
+
+// ExpiredAt returns when the subject of an Expired failure expired
+func ExpiredAt(e error) (time.Time, bool) {
+	v, ok := lookup(e, expiredAtKey{})
+	if !ok {
+		return time.Time{}, false
+	}
+	return v.(time.Time), true
+}
+
+// Overloaded is used to signal that the service is shedding load. Unlike a
+// System failure nothing is broken, callers should back off and try again.
+func Overloaded(format string, a ...interface{}) error {
+	return Wrap(KindOverloaded, format, a...)
+}
+
+// NewOverloaded is Overloaded carrying the backoff suggested to callers and
+// the queue depth that triggered the shedding, see RetryAfter and QueueDepth.
+func NewOverloaded(backoff time.Duration, depth int, format string, a ...interface{}) error {
+	err := WithRetryAfter(Overloaded(format, a...), backoff)
+	return annotate(err, queueDepthKey{}, depth)
+}
+
+func IsOverloaded(e error) bool {
+	return errors.Is(e, KindOverloaded)
+}
+
+func ToOverloaded(e error, format string, a ...interface{}) error {
+	cause := fromError(e, Overloaded)
+	return Wrap(cause, format, a...)
+}
+
+type queueDepthKey struct{}
+
+// QueueDepth returns the queue depth carried by an Overloaded failure
+func QueueDepth(e error) (int, bool) {
+	v, ok := lookup(e, queueDepthKey{})
+	if !ok {
+		return 0, false
+	}
+
+	return v.(int), true
+}
+
+// RateLimited is used to signal that the caller made more requests than it
+// is allowed to. Responses use 429 and the failure is retryable once the
+// limit resets.
+func RateLimited(format string, a ...interface{}) error {
+	return Wrap(KindRateLimited, format, a...)
+}
+
+// RateLimit describes the limit a RateLimited failure ran into, the way
+// the RateLimit-* response headers do.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the window
+	Limit int
+	// Remaining is the number of requests left in the window
+	Remaining int
+	// Reset is when the window resets
+	Reset time.Time
+}
+
+// NewRateLimited is RateLimited carrying the limit that was hit, see
+// RateLimitOf. Combine it with WithRetryAfter to tell clients how long to
+// wait.
+func NewRateLimited(limit RateLimit, format string, a ...interface{}) error {
+	return annotate(RateLimited(format, a...), rateLimitKey{}, limit)
+}
+
+func IsRateLimited(e error) bool {
+	return errors.Is(e, KindRateLimited)
+}
+
+func ToRateLimited(e error, format string, a ...interface{}) error {
+	cause := fromError(e, RateLimited)
+	return Wrap(cause, format, a...)
+}
+
+type rateLimitKey struct{}
+
+// RateLimitOf returns the limit carried by a RateLimited failure
+func RateLimitOf(e error) (RateLimit, bool) {
+	v, ok := lookup(e, rateLimitKey{})
+	if !ok {
+		return RateLimit{}, false
+	}
+
+	return v.(RateLimit), true
+}
+
+// Canceled is used to signal that the operation was abandoned before it
+// could finish, typically because the caller went away.
+func Canceled(format string, a ...interface{}) error {
+	return Wrap(KindCanceled, format, a...)
+}
 
-func (e err) Error() string {
-	return string(e)
+func IsCanceled(e error) bool {
+	return errors.Is(e, KindCanceled)
+}
+
+func ToCanceled(e error, format string, a ...interface{}) error {
+	cause := fromError(e, Canceled)
+	return Wrap(cause, format, a...)
 }
 
 // InvalidState is used to signal that the resource is not in a valid state
 func InvalidState(format string, a ...interface{}) error {
-	return Wrap(invalidStateErr, format, a...)
+	return Wrap(KindInvalidState, format, a...)
 }
 
 func IsInvalidState(e error) bool {
-	return errors.Is(e, invalidStateErr)
+	return errors.Is(e, KindInvalidState)
 }
 
 func ToInvalidState(e error, format string, a ...interface{}) error {
-	cause := InvalidState(e.Error())
+	cause := fromError(e, InvalidState)
 	return Wrap(cause, format, a...)
 }
 
 // NoChange is used to signal that if you expected something to change,
 // it has not.
 func NoChange(format string, a ...interface{}) error {
-	return Wrap(noChangeErr, format, a...)
+	return Wrap(KindNoChange, format, a...)
 }
 
 func IsNoChange(e error) bool {
-	return errors.Is(e, noChangeErr)
+	return errors.Is(e, KindNoChange)
 }
 
 func ToNoChange(e error, format string, a ...interface{}) error {
-	cause := NoChange(e.Error())
+	cause := fromError(e, NoChange)
 	return Wrap(cause, format, a...)
 }
 
 // Warn is used to signal that this error is only a warning. It can be
 // used instead of ignore to change the log level of a system
 func Warn(format string, a ...interface{}) error {
-	return Wrap(warnErr, format, a...)
+	return Wrap(KindWarn, format, a...)
 }
 
 func IsWarn(e error) bool {
-	return errors.Is(e, warnErr)
+	return errors.Is(e, KindWarn)
 }
 
 func ToWarn(e error, format string, a ...interface{}) error {
-	cause := Warn(e.Error())
+	cause := fromError(e, Warn)
 	return Wrap(cause, format, a...)
 }
 
 // OutOfRange is used to signal that the offset of a map is invalid or
 // some index for a list is incorrect
 func OutOfRange(format string, a ...interface{}) error {
-	return Wrap(outOfRangeErr, format, a...)
+	return Wrap(KindOutOfRange, format, a...)
 }
 
 func IsOutOfRange(e error) bool {
-	return errors.Is(e, outOfRangeErr)
+	return errors.Is(e, KindOutOfRange)
 }
 
 func ToOutOfRange(e error, format string, a ...interface{}) error {
-	cause := OutOfRange(e.Error())
+	cause := fromError(e, OutOfRange)
 	return Wrap(cause, format, a...)
 }
 
 // Panic is used in panic recovery blocks or to indicate that you should
 // panic if you receive this error
 func Panic(format string, a ...interface{}) error {
-	return Wrap(panicErr, format, a...)
+	return Wrap(KindPanic, format, a...)
 }
 
 func IsPanic(e error) bool {
-	return errors.Is(e, panicErr)
+	return errors.Is(e, KindPanic)
 }
 
 func ToPanic(e error, format string, a ...interface{}) error {
-	cause := Panic(e.Error())
+	cause := fromError(e, Panic)
 	return Wrap(cause, format, a...)
 }
 
 // MissingFromContext is used to indicate a resource was supposed to be in the
 // context but is missing
 func MissingFromContext(format string, a ...interface{}) error {
-	return Wrap(missingFromContextErr, format, a...)
+	return Wrap(KindMissingFromContext, format, a...)
 }
 
 func IsMissingFromContext(e error) bool {
-	return errors.Is(e, missingFromContextErr)
+	return errors.Is(e, KindMissingFromContext)
 }
 
 func ToMissingFromContext(e error, format string, a ...interface{}) error {
-	cause := MissingFromContext(e.Error())
+	cause := fromError(e, MissingFromContext)
 	return Wrap(cause, format, a...)
 }
 
 // AlreadyExists is used to indicate that the given resource already exists
 func AlreadyExists(format string, a ...interface{}) error {
-	return Wrap(alreadyExistsErr, format, a...)
+	return Wrap(KindAlreadyExists, format, a...)
 }
 
 func IsAlreadyExists(e error) bool {
-	return errors.Is(e, alreadyExistsErr)
+	return errors.Is(e, KindAlreadyExists)
 }
 
 func ToAlreadyExists(e error, format string, a ...interface{}) error {
-	cause := AlreadyExists(e.Error())
+	cause := fromError(e, AlreadyExists)
+	return Wrap(cause, format, a...)
+}
+
+// Conflict is used to signal that a write lost against a concurrent one,
+// a version or ETag mismatch or a failed compare and swap. Unlike
+// AlreadyExists the resource is not a duplicate, it changed since it was
+// read.
+func Conflict(format string, a ...interface{}) error {
+	return Wrap(KindConflict, format, a...)
+}
+
+func IsConflict(e error) bool {
+	return errors.Is(e, KindConflict)
+}
+
+func ToConflict(e error, format string, a ...interface{}) error {
+	cause := fromError(e, Conflict)
+	return Wrap(cause, format, a...)
+}
+
+// NotImplemented is used to signal an operation that is not supported, or
+// not enabled, such as a feature behind a flag that is off.
+func NotImplemented(format string, a ...interface{}) error {
+	return Wrap(KindNotImplemented, format, a...)
+}
+
+func IsNotImplemented(e error) bool {
+	return errors.Is(e, KindNotImplemented)
+}
+
+func ToNotImplemented(e error, format string, a ...interface{}) error {
+	cause := fromError(e, NotImplemented)
+	return Wrap(cause, format, a...)
+}
+
+// Unavailable is used to signal that a dependency can't be reached right
+// now, during an outage or a deploy. Unlike Overloaded the service itself
+// is fine, the failure is retryable.
+func Unavailable(format string, a ...interface{}) error {
+	return Wrap(KindUnavailable, format, a...)
+}
+
+func IsUnavailable(e error) bool {
+	return errors.Is(e, KindUnavailable)
+}
+
+func ToUnavailable(e error, format string, a ...interface{}) error {
+	cause := fromError(e, Unavailable)
+	return Wrap(cause, format, a...)
+}
+
+// ResourceExhausted is used to signal that a quota or a finite resource,
+// memory, disk or a connection pool, ran out. The failure is retryable
+// once the resource frees up.
+func ResourceExhausted(format string, a ...interface{}) error {
+	return Wrap(KindResourceExhausted, format, a...)
+}
+
+func IsResourceExhausted(e error) bool {
+	return errors.Is(e, KindResourceExhausted)
+}
+
+func ToResourceExhausted(e error, format string, a ...interface{}) error {
+	cause := fromError(e, ResourceExhausted)
+	return Wrap(cause, format, a...)
+}
+
+// PayloadTooLarge is used to signal a request body or message over the
+// accepted size, answered with 413.
+func PayloadTooLarge(format string, a ...interface{}) error {
+	return Wrap(KindPayloadTooLarge, format, a...)
+}
+
+func IsPayloadTooLarge(e error) bool {
+	return errors.Is(e, KindPayloadTooLarge)
+}
+
+func ToPayloadTooLarge(e error, format string, a ...interface{}) error {
+	cause := fromError(e, PayloadTooLarge)
 	return Wrap(cause, format, a...)
 }
 
 // Startup is used to signify a failure preventing the system from starting up
 func Startup(format string, a ...interface{}) error {
-	return Wrap(startupErr, format, a...)
+	return Wrap(KindStartup, format, a...)
 }
 
 func IsStartup(e error) bool {
-	return errors.Is(e, startupErr)
+	return errors.Is(e, KindStartup)
 }
 
 func ToStartup(e error, format string, a ...interface{}) error {
-	cause := Startup(e.Error())
+	cause := fromError(e, Startup)
 	return Wrap(cause, format, a...)
 }
 
 // Timeout is used to signify that error because something was taking
-// too long
+// too long. Timeout failures implement net.Error with Timeout reporting
+// true, so libraries checking for network timeouts recognize them.
 func Timeout(format string, a ...interface{}) error {
-	return Wrap(timeoutErr, format, a...)
+	return Wrap(KindTimeout, format, a...)
 }
 
+// IsTimeout reports whether e is a Timeout failure or wraps an error
+// reporting a timeout the standard way, a net.Error timeout,
+// os.ErrDeadlineExceeded or context.DeadlineExceeded.
 func IsTimeout(e error) bool {
-	return errors.Is(e, timeoutErr)
+	return errors.Is(e, KindTimeout) || isTimeout(e)
 }
 
 func ToTimeout(e error, format string, a ...interface{}) error {
-	cause := Timeout(e.Error())
+	cause := fromError(e, Timeout)
 	return Wrap(cause, format, a...)
 }
 
 // Config is used to signify that error occurred when processing the
 // application configuration
 func Config(format string, a ...interface{}) error {
-	return Wrap(configErr, format, a...)
+	return Wrap(KindConfig, format, a...)
 }
 
 func IsConfig(e error) bool {
-	return errors.Is(e, configErr)
+	return errors.Is(e, KindConfig)
 }
 
 func ToConfig(e error, format string, a ...interface{}) error {
-	cause := Config(e.Error())
+	cause := fromError(e, Config)
 	return Wrap(cause, format, a...)
 }
 
 // InvalidParam is to indicate that the param of a function or any
 // parameter in general is invalid
 func InvalidParam(format string, a ...interface{}) error {
-	return Wrap(invalidParamErr, format, a...)
+	return Wrap(KindInvalidParam, format, a...)
 }
 
 func IsInvalidParam(e error) bool {
-	return errors.Is(e, invalidParamErr)
+	return errors.Is(e, KindInvalidParam)
 }
 
 func ToInvalidParam(e error, format string, a ...interface{}) error {
-	cause := InvalidParam(e.Error())
+	cause := fromError(e, InvalidParam)
 	return Wrap(cause, format, a...)
 }
 
 // Ignore is used to signify that error should not be acted on, it's up
 // to the handler to decide to log these errors or not.
 func Ignore(format string, a ...interface{}) error {
-	return Wrap(ignoreErr, format, a...)
+	return Wrap(KindIgnore, format, a...)
 }
 
 func IsIgnore(e error) bool {
-	return errors.Is(e, ignoreErr)
+	return errors.Is(e, KindIgnore)
 }
 
-// ToIgnore converts `e` into the root cause of ignoreErr, it informs the
+// ToIgnore converts `e` into the root cause of KindIgnore, it informs the
 // system to ignore error. Used typically to log results and do not act on
 // the error itself.
 func ToIgnore(e error, format string, a ...interface{}) error {
-	cause := Ignore(e.Error())
+	cause := fromError(e, Ignore)
 	return Wrap(cause, format, a...)
 }
 
 // NotFound is used to signify that whatever resource you were looking for
 // does not exist and that fact it does not exist is an error.
 func NotFound(format string, a ...interface{}) error {
-	return Wrap(notFoundErr, format, a...)
+	return Wrap(KindNotFound, format, a...)
 }
 
 func IsNotFound(e error) bool {
-	return errors.Is(e, notFoundErr)
+	return errors.Is(e, KindNotFound)
 }
 
 func ToNotFound(e error, format string, a ...interface{}) error {
-	cause := NotFound(e.Error())
+	cause := fromError(e, NotFound)
 	return Wrap(cause, format, a...)
 }
 
 // NotAuthorized is used to signify that a resource does not have sufficient
 // access to perform a given task
 func NotAuthorized(format string, a ...interface{}) error {
-	return Wrap(notAuthorizedErr, format, a...)
+	return Wrap(KindNotAuthorized, format, a...)
 }
 
 func IsNotAuthorized(e error) bool {
-	return errors.Is(e, notAuthorizedErr)
+	return errors.Is(e, KindNotAuthorized)
 }
 
 func ToNotAuthorized(e error, format string, a ...interface{}) error {
-	cause := NotAuthorized(e.Error())
+	cause := fromError(e, NotAuthorized)
 	return Wrap(cause, format, a...)
 }
 
 // NotAuthenticated is used to signify that a resource's identity verification
 // failed. They are not who they claim to be
 func NotAuthenticated(format string, a ...interface{}) error {
-	return Wrap(notAuthenticatedErr, format, a...)
+	return Wrap(KindNotAuthenticated, format, a...)
 }
 
 func IsNotAuthenticated(e error) bool {
-	return errors.Is(e, notAuthenticatedErr)
+	return errors.Is(e, KindNotAuthenticated)
 }
 
 func ToNotAuthenticated(e error, format string, a ...interface{}) error {
-	cause := NotAuthenticated(e.Error())
+	cause := fromError(e, NotAuthenticated)
 	return Wrap(cause, format, a...)
 }
 
 // Forbidden is used to signify either not authenticated or
 // not authorized
 func Forbidden(format string, a ...interface{}) error {
-	return Wrap(forbiddenErr, format, a...)
+	return Wrap(KindForbidden, format, a...)
 }
 
 func IsForbidden(e error) bool {
-	return errors.Is(e, forbiddenErr)
+	return errors.Is(e, KindForbidden)
 }
 
 func ToForbidden(e error, format string, a ...interface{}) error {
-	cause := Forbidden(e.Error())
+	cause := fromError(e, Forbidden)
 	return Wrap(cause, format, a...)
 }
 
@@ -313,79 +554,176 @@ func IsAnyAuthFailure(e error) bool {
 
 // Validation is used to signify that a validation rule as been violated
 func Validation(format string, a ...interface{}) error {
-	return Wrap(validationErr, format, a...)
+	return Wrap(KindValidation, format, a...)
 }
 
 func IsValidation(e error) bool {
-	return errors.Is(e, validationErr)
+	return errors.Is(e, KindValidation)
 }
 
 func ToValidation(e error, format string, a ...interface{}) error {
-	cause := Validation(e.Error())
+	cause := fromError(e, Validation)
 	return Wrap(cause, format, a...)
 }
 
 // Defer is used to signify errors that originate inside a defer function
 func Defer(format string, a ...interface{}) error {
-	return Wrap(deferErr, format, a...)
+	return Wrap(KindDefer, format, a...)
 }
 
 func IsDefer(e error) bool {
-	return errors.Is(e, deferErr)
+	return errors.Is(e, KindDefer)
 }
 
 func ToDefer(e error, format string, a ...interface{}) error {
-	cause := Defer(e.Error())
+	cause := fromError(e, Defer)
 	return Wrap(cause, format, a...)
 }
 
+// CaptureDefer classifies the error errp points to as Defer, with the
+// message, when a deferred call has set it. The original failure stays in
+// the chain so errors.Is and errors.As still find it:
+//
+//	func (s *Store) Close() (err error) {
+//		defer failure.CaptureDefer(&err, "closing db")
+//		defer failure.AppendClose(&err, s.db)
+//		...
+//	}
+//
+// Nothing happens when errp or the error it points to is nil.
+func CaptureDefer(errp *error, format string, a ...interface{}) {
+	if errp == nil || *errp == nil {
+		return
+	}
+
+	*errp = To(KindDefer, *errp, format, a...)
+}
+
 // Shutdown is used to signal that the app should shut down.
 func Shutdown(format string, a ...interface{}) error {
-	return Wrap(shutdownErr, format, a...)
+	return Wrap(KindShutdown, format, a...)
 }
 
 func ToShutdown(e error, format string, a ...interface{}) error {
-	cause := Shutdown(e.Error())
+	cause := fromError(e, Shutdown)
 	return Wrap(cause, format, a...)
 }
 
 func IsShutdown(e error) bool {
-	return errors.Is(e, shutdownErr)
+	return errors.Is(e, KindShutdown)
 }
 
 // Server has the same meaning as Platform or System, it can be used instead if you
 // don't like how Platform or System reads in your code.
 func Server(format string, a ...interface{}) error {
-	return Wrap(serverErr, format, a...)
+	return Wrap(KindServer, format, a...)
 }
 
-// IsServer will return true if the cause is a serverErr
+// IsServer will return true if the cause is a KindServer
 func IsServer(err error) bool {
-	return errors.Is(err, serverErr)
+	return errors.Is(err, KindServer)
 }
 
 func ToServer(e error, format string, a ...interface{}) error {
-	cause := Server(e.Error())
+	cause := fromError(e, Server)
 	return Wrap(cause, format, a...)
 }
 
 // System is has the same meaning as Platform or Server, it can be used instead if you
 // don't like how Platform reads in your code
 func System(format string, a ...interface{}) error {
-	return Wrap(systemErr, format, a...)
+	return Wrap(KindSystem, format, a...)
 }
 
 func IsSystem(err error) bool {
-	return errors.Is(err, systemErr)
+	return errors.Is(err, KindSystem)
 }
 
 func ToSystem(e error, format string, a ...interface{}) error {
-	cause := System(e.Error())
+	cause := fromError(e, System)
 	return Wrap(cause, format, a...)
 }
 
-// Wrap expose errors.Wrapf as our default wrapping style
+// Wrap expose errors.Wrapf as our default wrapping style. Every constructor
+// of this package goes through it, so it is where the stack trace is
+// captured when EnableStackTraces is on. msg is only formatted when
+// arguments are given or it holds a %, escaped ones included, it is used as
+// is otherwise. Wrapping a nil error leaves only the message.
 func Wrap(err error, msg string, a ...interface{}) error {
-	msg = fmt.Sprintf(msg, a...)
-	return fmt.Errorf("%s: %w", msg, err)
+	if len(a) > 0 || strings.IndexByte(msg, '%') >= 0 {
+		msg = fmt.Sprintf(msg, a...)
+	}
+	if err != nil {
+		msg += ": " + err.Error()
+	}
+	s, _ := truncate(msg)
+
+	return markTimeout(captureStack(&wrapped{msg: s, err: err}), err)
+}
+
+// LazyWrap is Wrap formatting the message only when Error is first called,
+// with the arguments returned by args, so expensive arguments such as
+// payload dumps cost nothing when the failure is ignored or its log entry
+// sampled out. Categories are errors, so it builds failures too:
+//
+//	return failure.LazyWrap(failure.KindValidation, "payload %s rejected", func() []interface{} {
+//		return []interface{}{dump(payload)}
+//	})
+//
+// args may run on any goroutine, it must not depend on state that changes
+// after the call. Wrap renders the errors it wraps, wrap a lazy failure
+// with LazyWrap to keep it lazy.
+func LazyWrap(err error, format string, args func() []interface{}) error {
+	return markTimeout(captureStack(&lazy{format: format, args: args, err: err}), err)
+}
+
+// Lazy is LazyWrap without a wrapped error
+func Lazy(format string, args func() []interface{}) error {
+	return captureStack(&lazy{format: format, args: args})
+}
+
+// lazy is an error wrapped by LazyWrap, its message is rendered the first
+// time it is needed.
+type lazy struct {
+	format string
+	args   func() []interface{}
+	err    error
+
+	once sync.Once
+	msg  string
+}
+
+func (l *lazy) Error() string {
+	l.once.Do(func() {
+		var a []interface{}
+		if l.args != nil {
+			a = l.args()
+		}
+		msg := fmt.Sprintf(l.format, a...)
+		if l.err != nil {
+			msg += ": " + l.err.Error()
+		}
+		l.msg, _ = truncate(msg)
+	})
+
+	return l.msg
+}
+
+func (l *lazy) Unwrap() error {
+	return l.err
+}
+
+// wrapped is an error wrapped by Wrap, its message is rendered once and
+// may have been cut by truncate.
+type wrapped struct {
+	msg string
+	err error
+}
+
+func (w *wrapped) Error() string {
+	return w.msg
+}
+
+func (w *wrapped) Unwrap() error {
+	return w.err
 }
@@ -3,12 +3,152 @@ package failure_test
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/rsb/failure"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestNew(t *testing.T) {
+	err := failure.New(failure.KindNotFound, "user %d", 1)
+	assert.True(t, failure.IsNotFound(err))
+	assert.Equal(t, failure.NotFound("user %d", 1).Error(), err.Error())
+
+	kind, ok := failure.Kind(failure.New(failure.Category("custom"), "foo"))
+	assert.True(t, ok)
+	assert.Equal(t, failure.Category("custom"), kind)
+}
+
+func TestTo(t *testing.T) {
+	api := errors.New("connection reset")
+	err := failure.To(failure.KindTimeout, api, "call %s", "billing")
+	assert.True(t, failure.IsTimeout(err))
+	assert.True(t, errors.Is(err, api))
+	assert.Equal(t, "call billing: connection reset: "+failure.TimeoutMsg, err.Error())
+
+	assert.Nil(t, failure.To(failure.KindTimeout, nil, "foo"))
+}
+
+func TestExpired(t *testing.T) {
+	err := failure.Expired("signed url")
+	assert.Contains(t, err.Error(), failure.ExpiredMsg)
+	assert.True(t, failure.IsExpired(err))
+	assert.False(t, failure.IsNotFound(err))
+	assert.False(t, failure.IsExpired(errors.New("something else")))
+
+	_, ok := failure.ExpiredAt(err)
+	assert.False(t, ok)
+
+	err = failure.ToExpired(errors.New("jwt exp claim"), "verify token")
+	assert.True(t, failure.IsExpired(err))
+}
+
+func TestNewExpired(t *testing.T) {
+	at := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	err := failure.NewExpired(failure.ExpiredLicense, at, "license %s", "ABC")
+	assert.True(t, failure.IsExpired(err))
+	assert.Equal(t, "license ABC: "+failure.ExpiredMsg, err.Error())
+
+	subject, ok := failure.ExpiredSubject(err)
+	assert.True(t, ok)
+	assert.Equal(t, failure.ExpiredLicense, subject)
+
+	expiredAt, ok := failure.ExpiredAt(err)
+	assert.True(t, ok)
+	assert.Equal(t, at, expiredAt)
+}
+
+func TestOverloaded(t *testing.T) {
+	err := failure.Overloaded("queue is full")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), failure.OverloadedMsg)
+
+	assert.True(t, failure.IsOverloaded(err))
+	assert.True(t, failure.IsRetryable(err))
+	assert.False(t, failure.IsOverloaded(errors.New("something else")))
+
+	_, ok := failure.QueueDepth(err)
+	assert.False(t, ok)
+}
+
+func TestNewOverloaded(t *testing.T) {
+	err := failure.NewOverloaded(2*time.Second, 512, "shedding %s", "ingest")
+	assert.True(t, failure.IsOverloaded(err))
+	assert.Equal(t, "shedding ingest: "+failure.OverloadedMsg, err.Error())
+
+	d, ok := failure.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+
+	depth, ok := failure.QueueDepth(err)
+	assert.True(t, ok)
+	assert.Equal(t, 512, depth)
+}
+
+func TestToOverloaded(t *testing.T) {
+	msg := "api specific msg"
+	e := errors.New(msg)
+
+	err := failure.ToOverloaded(e, "too many requests in flight")
+	assert.True(t, failure.IsOverloaded(err))
+
+	expected := "too many requests in flight: api specific msg: " + failure.OverloadedMsg
+	assert.Equal(t, err.Error(), expected)
+}
+
+func TestRateLimited(t *testing.T) {
+	err := failure.RateLimited("tenant %s", "acme")
+	assert.Equal(t, "tenant acme: "+failure.RateLimitedMsg, err.Error())
+	assert.True(t, failure.IsRateLimited(err))
+	assert.True(t, failure.IsRetryable(err))
+	assert.Equal(t, http.StatusTooManyRequests, failure.HTTPStatus(err))
+	assert.False(t, failure.IsRateLimited(errors.New("something else")))
+
+	_, ok := failure.RateLimitOf(err)
+	assert.False(t, ok)
+
+	reset := time.Date(2022, 5, 26, 12, 0, 0, 0, time.UTC)
+	err = failure.NewRateLimited(failure.RateLimit{Limit: 100, Reset: reset}, "tenant %s", "acme")
+	assert.True(t, failure.IsRateLimited(err))
+	limit, ok := failure.RateLimitOf(failure.Wrap(err, "create user"))
+	assert.True(t, ok)
+	assert.Equal(t, failure.RateLimit{Limit: 100, Remaining: 0, Reset: reset}, limit)
+
+	err = failure.ToRateLimited(errors.New("429 from billing"), "charge")
+	assert.True(t, failure.IsRateLimited(err))
+	assert.Equal(t, "charge: 429 from billing: "+failure.RateLimitedMsg, err.Error())
+
+	err = failure.TooManyRequests(time.Second, "slow down")
+	kind, _ := failure.Kind(err)
+	assert.Equal(t, failure.KindRateLimited, kind)
+	assert.True(t, failure.IsRateLimited(err))
+	assert.True(t, failure.IsRateLimited(failure.Wrap(err, "create user")))
+	assert.True(t, failure.IsAny(failure.BadRequest("x"), failure.KindBadRequest))
+}
+
+func TestCanceled(t *testing.T) {
+	err := failure.Canceled("client went away")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), failure.CanceledMsg)
+
+	assert.True(t, failure.IsCanceled(err))
+	assert.False(t, failure.IsCanceled(errors.New("something else")))
+}
+
+func TestToCanceled(t *testing.T) {
+	msg := "api specific msg"
+	e := errors.New(msg)
+
+	err := failure.ToCanceled(e, "request abandoned")
+	assert.Error(t, err)
+	assert.True(t, failure.IsCanceled(err))
+
+	expected := "request abandoned: api specific msg: " + failure.CanceledMsg
+	assert.Equal(t, err.Error(), expected)
+}
+
 func TestInvalidState(t *testing.T) {
 	msg := "something is not right"
 	err := failure.InvalidState(msg)
@@ -163,6 +303,72 @@ func TestToAlreadyExists(t *testing.T) {
 	assert.Equal(t, err.Error(), expected)
 }
 
+func TestConflict(t *testing.T) {
+	err := failure.Conflict("order 7 changed since version %d", 3)
+	assert.Equal(t, "order 7 changed since version 3: "+failure.ConflictMsg, err.Error())
+	assert.True(t, failure.IsConflict(err))
+	assert.False(t, failure.IsAlreadyExists(err))
+	assert.Equal(t, http.StatusConflict, failure.HTTPStatus(err))
+	assert.False(t, failure.IsConflict(errors.New("something else")))
+}
+
+func TestToConflict(t *testing.T) {
+	e := errors.New("etag mismatch")
+
+	err := failure.ToConflict(e, "update order")
+	assert.True(t, failure.IsConflict(err))
+	assert.Equal(t, "update order: etag mismatch: "+failure.ConflictMsg, err.Error())
+}
+
+func TestNotImplemented(t *testing.T) {
+	err := failure.NotImplemented("export to %s", "pdf")
+	assert.Equal(t, "export to pdf: "+failure.NotImplementedMsg, err.Error())
+	assert.True(t, failure.IsNotImplemented(err))
+	assert.Equal(t, http.StatusNotImplemented, failure.HTTPStatus(err))
+	assert.False(t, failure.IsNotImplemented(errors.New("something else")))
+
+	err = failure.ToNotImplemented(errors.New("flag off"), "export")
+	assert.True(t, failure.IsNotImplemented(err))
+	assert.Equal(t, "export: flag off: "+failure.NotImplementedMsg, err.Error())
+}
+
+func TestUnavailable(t *testing.T) {
+	err := failure.Unavailable("billing")
+	assert.Equal(t, "billing: "+failure.UnavailableMsg, err.Error())
+	assert.True(t, failure.IsUnavailable(err))
+	assert.True(t, failure.IsRetryable(err))
+	assert.Equal(t, http.StatusServiceUnavailable, failure.HTTPStatus(err))
+	assert.False(t, failure.IsUnavailable(errors.New("something else")))
+
+	err = failure.ToUnavailable(errors.New("connection refused"), "charge")
+	assert.True(t, failure.IsUnavailable(err))
+	assert.Equal(t, "charge: connection refused: "+failure.UnavailableMsg, err.Error())
+}
+
+func TestResourceExhausted(t *testing.T) {
+	err := failure.ResourceExhausted("pool %s", "db")
+	assert.Equal(t, "pool db: "+failure.ResourceExhaustedMsg, err.Error())
+	assert.True(t, failure.IsResourceExhausted(err))
+	assert.True(t, failure.IsRetryable(err))
+	assert.False(t, failure.IsResourceExhausted(errors.New("something else")))
+
+	err = failure.ToResourceExhausted(errors.New("too many clients"), "connect")
+	assert.True(t, failure.IsResourceExhausted(err))
+	assert.Equal(t, "connect: too many clients: "+failure.ResourceExhaustedMsg, err.Error())
+}
+
+func TestPayloadTooLarge(t *testing.T) {
+	err := failure.PayloadTooLarge("upload of %d bytes", 2048)
+	assert.Equal(t, "upload of 2048 bytes: "+failure.PayloadTooLargeMsg, err.Error())
+	assert.True(t, failure.IsPayloadTooLarge(err))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, failure.HTTPStatus(err))
+	assert.False(t, failure.IsPayloadTooLarge(errors.New("something else")))
+
+	err = failure.ToPayloadTooLarge(errors.New("limit 1024"), "upload")
+	assert.True(t, failure.IsPayloadTooLarge(err))
+	assert.Equal(t, "upload: limit 1024: "+failure.PayloadTooLargeMsg, err.Error())
+}
+
 func TestStartup(t *testing.T) {
 	msg := "some message"
 	err := failure.Startup(msg)
@@ -512,3 +718,91 @@ func TestDefer(t *testing.T) {
 
 	assert.True(t, failure.IsDefer(err))
 }
+
+func TestCaptureDefer(t *testing.T) {
+	flush := errors.New("flush failed")
+	closeDB := func(closeErr error) (err error) {
+		defer failure.CaptureDefer(&err, "closing %s", "db")
+		defer failure.AppendClose(&err, &closer{err: closeErr})
+		return nil
+	}
+
+	err := closeDB(flush)
+	assert.True(t, failure.IsDefer(err))
+	assert.True(t, errors.Is(err, flush))
+	assert.Equal(t, "closing db: flush failed: "+failure.DeferMsg, err.Error())
+
+	assert.NoError(t, closeDB(nil))
+	assert.NotPanics(t, func() { failure.CaptureDefer(nil, "noop") })
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("connection reset")
+
+	err := failure.Wrap(cause, "read body of %s", "orders")
+	assert.Equal(t, "read body of orders: connection reset", err.Error())
+	assert.True(t, errors.Is(err, cause))
+
+	// without a verb the message is used as is, escaped percents still are
+	assert.Equal(t, "read body: connection reset", failure.Wrap(cause, "read body").Error())
+	assert.Equal(t, "50% done: connection reset", failure.Wrap(cause, "50%% done").Error())
+	assert.Equal(t, "100% sure: "+failure.NotFoundMsg, failure.NotFound("100%% sure").Error())
+	assert.Equal(t, "read body", failure.Wrap(nil, "read body").Error())
+
+	err = failure.ToSystem(errors.New("disk 100% full"), "write")
+	assert.Equal(t, "write: disk 100% full: "+failure.SystemMsg, err.Error())
+}
+
+func TestLazyWrap(t *testing.T) {
+	calls := 0
+	args := func() []interface{} {
+		calls++
+		return []interface{}{"{...}"}
+	}
+
+	err := failure.LazyWrap(failure.KindValidation, "payload %s rejected", args)
+	assert.True(t, failure.IsValidation(err))
+	assert.Zero(t, calls)
+
+	assert.Equal(t, "payload {...} rejected: "+failure.ValidationMsg, err.Error())
+	assert.Equal(t, "payload {...} rejected: "+failure.ValidationMsg, err.Error())
+	assert.Equal(t, 1, calls)
+
+	err = failure.Wrap(failure.Lazy("payload %s", args), "decode")
+	assert.Equal(t, "decode: payload {...}", err.Error())
+	assert.Equal(t, 2, calls)
+
+	var ne interface{ Timeout() bool }
+	assert.True(t, errors.As(failure.LazyWrap(failure.KindTimeout, "slow", nil), &ne))
+}
+
+func BenchmarkWrap(b *testing.B) {
+	cause := errors.New("connection reset")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = failure.Wrap(cause, "read body")
+	}
+}
+
+func BenchmarkWrap_Args(b *testing.B) {
+	cause := errors.New("connection reset")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = failure.Wrap(cause, "read body of %s", "orders")
+	}
+}
+
+func BenchmarkNotFound(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = failure.NotFound("user")
+	}
+}
+
+func BenchmarkToSystem(b *testing.B) {
+	cause := errors.New("connection reset")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = failure.ToSystem(cause, "read body")
+	}
+}
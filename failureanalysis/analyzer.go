@@ -0,0 +1,112 @@
+// Package failureanalysis provides a go/analysis checker that flags functions
+// returning unclassified errors, built with errors.New or fmt.Errorf, in
+// packages that have opted into failure classification.
+//
+// A package opts in by placing the directive
+//
+//	//failure:classify
+//
+// in any of its files, usually next to the package clause in doc.go.
+// fmt.Errorf calls using %w are not reported since they keep the category of
+// the error they wrap.
+package failureanalysis
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Directive is the comment that opts a package into the check
+const Directive = "//failure:classify"
+
+// Analyzer reports return statements producing raw errors
+var Analyzer = &analysis.Analyzer{
+	Name:     "failureanalysis",
+	Doc:      "report functions returning errors.New or fmt.Errorf errors in packages that opted into failure categories",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if !optedIn(pass.Files) {
+		return nil, nil
+	}
+
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.ReturnStmt)(nil)}, func(n ast.Node) {
+		for _, r := range n.(*ast.ReturnStmt).Results {
+			call, ok := unparen(r).(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if name := rawConstructor(pass.TypesInfo, call); name != "" {
+				pass.Reportf(call.Pos(), "unclassified error returned from %s, use a failure category", name)
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+func optedIn(files []*ast.File) bool {
+	for _, f := range files {
+		for _, g := range f.Comments {
+			for _, c := range g.List {
+				if strings.TrimSpace(c.Text) == Directive {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// rawConstructor returns the name of the standard library function used by
+// call when it builds an unclassified error.
+func rawConstructor(info *types.Info, call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+
+	switch fn.Pkg().Path() + "." + fn.Name() {
+	case "errors.New":
+		return "errors.New"
+	case "fmt.Errorf":
+		if len(call.Args) > 0 && wraps(info, call.Args[0]) {
+			return ""
+		}
+		return "fmt.Errorf"
+	}
+
+	return ""
+}
+
+// wraps reports whether the format string is known to contain %w
+func wraps(info *types.Info, format ast.Expr) bool {
+	tv, ok := info.Types[format]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return false
+	}
+	return strings.Contains(constant.StringVal(tv.Value), "%w")
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
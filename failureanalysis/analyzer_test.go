@@ -0,0 +1,12 @@
+package failureanalysis_test
+
+import (
+	"testing"
+
+	"github.com/rsb/failure/failureanalysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), failureanalysis.Analyzer, "strict", "loose")
+}
@@ -0,0 +1,14 @@
+// Command failurevet runs the failureanalysis checker, either on its own or
+// through go vet:
+//
+//	go vet -vettool=$(which failurevet) ./...
+package main
+
+import (
+	"github.com/rsb/failure/failureanalysis"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(failureanalysis.Analyzer)
+}
@@ -0,0 +1,7 @@
+package failure
+
+import "fmt"
+
+func NotFound(format string, a ...interface{}) error {
+	return fmt.Errorf(format, a...)
+}
@@ -0,0 +1,7 @@
+package loose
+
+import "errors"
+
+func Raw() error {
+	return errors.New("boom")
+}
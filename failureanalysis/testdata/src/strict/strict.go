@@ -0,0 +1,33 @@
+//failure:classify
+package strict
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rsb/failure"
+)
+
+var ErrSentinel = errors.New("sentinel")
+
+func Raw() error {
+	return errors.New("boom") // want `unclassified error returned from errors.New`
+}
+
+func Formatted(id int) error {
+	return fmt.Errorf("user %d missing", id) // want `unclassified error returned from fmt.Errorf`
+}
+
+func Wrapped(err error) error {
+	return fmt.Errorf("load failed: %w", err)
+}
+
+func Classified(id int) (int, error) {
+	return 0, failure.NotFound("user %d", id)
+}
+
+func Closure() func() error {
+	return func() error {
+		return (errors.New("inner")) // want `unclassified error returned from errors.New`
+	}
+}
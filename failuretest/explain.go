@@ -0,0 +1,86 @@
+// Package failuretest provides helpers for tests that make assertions about
+// failures.
+package failuretest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rsb/failure"
+)
+
+// Explain describes why err does or does not match target, listing every
+// layer of the error chain along with the categories that were actually
+// found. It is meant to be used as the message of a failing assertion:
+//
+//	assert.True(t, failure.IsNotFound(err), failuretest.Explain(err, failure.KindNotFound))
+//
+// target is either an error, matched with errors.Is, or a non-nil pointer
+// to an interface or to a type implementing error, matched with errors.As.
+func Explain(err error, target interface{}) string {
+	var b strings.Builder
+
+	switch t := target.(type) {
+	case error:
+		fmt.Fprintf(&b, "errors.Is(err, %s) is %t\n", describe(t), errors.Is(err, t))
+	default:
+		if !validTarget(target) {
+			return fmt.Sprintf("target must be an error or a non-nil pointer to an error or interface type, got %T", target)
+		}
+		fmt.Fprintf(&b, "errors.As(err, %T) is %t\n", target, errors.As(err, target))
+	}
+
+	if err == nil {
+		b.WriteString("err is nil\n")
+		return b.String()
+	}
+
+	var kinds []string
+	var lines []string
+	failure.WalkDepth(err, func(e error, depth int) bool {
+		if c, ok := e.(failure.Category); ok {
+			kinds = append(kinds, c.String())
+		}
+		lines = append(lines, fmt.Sprintf("%s%s", strings.Repeat("  ", depth+2), describe(e)))
+		return true
+	})
+
+	if len(kinds) == 0 {
+		b.WriteString("categories found: none\n")
+	} else {
+		fmt.Fprintf(&b, "categories found: %s\n", strings.Join(kinds, ", "))
+	}
+
+	b.WriteString("chain:\n")
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// validTarget reports whether errors.As accepts target without panicking
+func validTarget(target interface{}) bool {
+	v := reflect.ValueOf(target)
+	if target == nil || v.Kind() != reflect.Ptr || v.IsNil() {
+		return false
+	}
+
+	elem := v.Type().Elem()
+	return elem.Kind() == reflect.Interface || elem.Implements(errorType)
+}
+
+func describe(e error) string {
+	if c, ok := e.(failure.Category); ok {
+		return fmt.Sprintf("category %s (%q)", c.String(), c.Error())
+	}
+
+	if r, ok := e.(*failure.RestAPI); ok {
+		return fmt.Sprintf("%T status %d %q", e, r.StatusCode, r.Msg)
+	}
+
+	return fmt.Sprintf("%T %q", e, e.Error())
+}
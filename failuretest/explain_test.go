@@ -0,0 +1,62 @@
+package failuretest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/rsb/failure/failuretest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain_Is(t *testing.T) {
+	err := failure.Wrap(failure.Validation("user.ID is empty"), "load user")
+
+	out := failuretest.Explain(err, failure.KindNotFound)
+	assert.Contains(t, out, "errors.Is(err, category not_found (\"not found failure\")) is false")
+	assert.Contains(t, out, "categories found: validation")
+	assert.Contains(t, out, "load user: user.ID is empty: validation failure")
+
+	out = failuretest.Explain(err, failure.KindValidation)
+	assert.Contains(t, out, "is true")
+}
+
+func TestExplain_As(t *testing.T) {
+	err := failure.Wrap(failure.BadRequest("bad input"), "decode")
+
+	var r *failure.RestAPI
+	out := failuretest.Explain(err, &r)
+	assert.Contains(t, out, "errors.As(err, **failure.RestAPI) is true")
+	assert.Contains(t, out, "status 400")
+	assert.Contains(t, out, "categories found: bad_request")
+
+	out = failuretest.Explain(errors.New("foo"), &r)
+	assert.Contains(t, out, "is false")
+	assert.Contains(t, out, "categories found: none")
+}
+
+func TestExplain_Multi(t *testing.T) {
+	err := failure.Multiple([]error{
+		failure.Timeout("slow"),
+		failure.System("broken"),
+	})
+
+	out := failuretest.Explain(err, failure.KindNotFound)
+	assert.Contains(t, out, "categories found: timeout, system")
+}
+
+func TestExplain_InvalidTarget(t *testing.T) {
+	out := failuretest.Explain(errors.New("foo"), "not a target")
+	assert.Contains(t, out, "target must be an error or a non-nil pointer")
+
+	var n int
+	out = failuretest.Explain(errors.New("foo"), &n)
+	assert.Contains(t, out, "target must be an error or a non-nil pointer")
+
+	var m map[string]int
+	out = failuretest.Explain(errors.New("foo"), &m)
+	assert.Contains(t, out, "target must be an error or a non-nil pointer")
+
+	out = failuretest.Explain(nil, failure.KindNotFound)
+	assert.Contains(t, out, "err is nil")
+}
@@ -0,0 +1,85 @@
+package failure
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var normalizers = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`0[xX][0-9a-fA-F]+`), "<hex>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8,}\b`), "<hex>"},
+	{regexp.MustCompile(`[0-9]+`), "<n>"},
+}
+
+// Normalizer removes the variable parts of a message Fingerprint doesn't
+// know about, such as order numbers or tenant names, see SetNormalizers.
+type Normalizer func(msg string) string
+
+var customNormalizers = struct {
+	sync.RWMutex
+	list []Normalizer
+}{}
+
+// SetNormalizers sets the normalizers Fingerprint applies to messages
+// before its own, which replace uuids, hex values and numbers, so they
+// still see the message as it was. Calling it again replaces them, no
+// arguments removes them:
+//
+//	order := regexp.MustCompile(`ORD-[A-Z0-9]+`)
+//	failure.SetNormalizers(func(msg string) string {
+//		return order.ReplaceAllString(msg, "<order>")
+//	})
+func SetNormalizers(n ...Normalizer) {
+	customNormalizers.Lock()
+	defer customNormalizers.Unlock()
+	customNormalizers.list = append([]Normalizer(nil), n...)
+}
+
+// Fingerprint returns a stable identifier for the kind of failure e is, so
+// that occurrences of the same failure can be grouped together. It is built
+// from the category, the operations added with Op and the message with
+// variable parts like ids, numbers and uuids normalized away, so
+// "user 42 not found" and "user 7 not found" share a fingerprint.
+func Fingerprint(e error) string {
+	if e == nil {
+		return ""
+	}
+
+	var kind string
+	if c, ok := Kind(e); ok {
+		kind = c.String()
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(kind))
+	_, _ = h.Write([]byte{0})
+	if ops := Ops(e); len(ops) > 0 {
+		_, _ = h.Write([]byte(strings.Join(ops, "/")))
+		_, _ = h.Write([]byte{0})
+	}
+	_, _ = h.Write([]byte(normalizeMessage(e.Error())))
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func normalizeMessage(msg string) string {
+	customNormalizers.RLock()
+	custom := customNormalizers.list
+	customNormalizers.RUnlock()
+
+	for _, fn := range custom {
+		msg = fn(msg)
+	}
+	for _, n := range normalizers {
+		msg = n.re.ReplaceAllString(msg, n.repl)
+	}
+
+	return msg
+}
@@ -0,0 +1,55 @@
+package failure_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := failure.NotFound("user 42 not found")
+	b := failure.NotFound("user 7 not found")
+	c := failure.System("user 42 not found")
+	d := failure.NotFound("order 42 not found")
+
+	assert.Equal(t, failure.Fingerprint(a), failure.Fingerprint(b))
+	assert.NotEqual(t, failure.Fingerprint(a), failure.Fingerprint(c))
+	assert.NotEqual(t, failure.Fingerprint(a), failure.Fingerprint(d))
+	assert.Len(t, failure.Fingerprint(a), 16)
+
+	u1 := errors.New("session 3f2b9c1e-8d4a-4b7e-9f10-2c3d4e5f6a7b expired at 0xc000123")
+	u2 := errors.New("session 00000000-1111-2222-3333-444444444444 expired at 0xc000999")
+	assert.Equal(t, failure.Fingerprint(u1), failure.Fingerprint(u2))
+
+	assert.Empty(t, failure.Fingerprint(nil))
+}
+
+func TestFingerprint_Ops(t *testing.T) {
+	a := failure.Op("orders.Create", failure.Op("db.Insert", failure.System("insert order 42")))
+	b := failure.Op("orders.Create", failure.Op("db.Insert", failure.System("insert order 7")))
+	c := failure.Op("orders.Update", failure.Op("db.Insert", failure.System("insert order 42")))
+
+	assert.Equal(t, failure.Fingerprint(a), failure.Fingerprint(b))
+	assert.NotEqual(t, failure.Fingerprint(a), failure.Fingerprint(c))
+
+	plain := failure.NotFound("user 42 not found")
+	assert.Equal(t, failure.Fingerprint(plain), failure.Fingerprint(failure.NotFound("user 7 not found")))
+}
+
+func TestSetNormalizers(t *testing.T) {
+	a := failure.NotFound("tenant acme has no plan")
+	b := failure.NotFound("tenant globex has no plan")
+	assert.NotEqual(t, failure.Fingerprint(a), failure.Fingerprint(b))
+
+	tenant := regexp.MustCompile(`tenant \w+`)
+	failure.SetNormalizers(func(msg string) string {
+		return tenant.ReplaceAllString(msg, "tenant <tenant>")
+	})
+	assert.Equal(t, failure.Fingerprint(a), failure.Fingerprint(b))
+
+	failure.SetNormalizers()
+	assert.NotEqual(t, failure.Fingerprint(a), failure.Fingerprint(b))
+}
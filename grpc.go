@@ -0,0 +1,67 @@
+package failure
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcCategories is the category a gRPC code is read back as, when several
+// categories share a code the most general one is used.
+var grpcCategories = map[codes.Code]Category{
+	codes.Canceled:           KindCanceled,
+	codes.Unknown:            KindSystem,
+	codes.InvalidArgument:    KindValidation,
+	codes.DeadlineExceeded:   KindTimeout,
+	codes.NotFound:           KindNotFound,
+	codes.AlreadyExists:      KindAlreadyExists,
+	codes.PermissionDenied:   KindNotAuthorized,
+	codes.ResourceExhausted:  KindResourceExhausted,
+	codes.FailedPrecondition: KindInvalidState,
+	codes.Aborted:            KindConflict,
+	codes.OutOfRange:         KindOutOfRange,
+	codes.Unimplemented:      KindNotImplemented,
+	codes.Internal:           KindSystem,
+	codes.Unavailable:        KindUnavailable,
+	codes.DataLoss:           KindSystem,
+	codes.Unauthenticated:    KindNotAuthenticated,
+}
+
+// GRPCCode maps e to the gRPC status code of its category, NotFound is
+// NotFound, Validation is InvalidArgument, Timeout is DeadlineExceeded and
+// so on, see Taxonomy for the full table. Errors without a category are
+// Unknown and nil is OK, so handlers shared with REST answer with:
+//
+//	if err != nil {
+//		return nil, status.Error(failure.GRPCCode(err), err.Error())
+//	}
+func GRPCCode(e error) codes.Code {
+	if e == nil {
+		return codes.OK
+	}
+
+	if c, ok := Kind(e); ok {
+		if info, ok := categoryInfoOf(c); ok {
+			return info.grpc
+		}
+	}
+
+	return codes.Unknown
+}
+
+// FromGRPCStatus rebuilds the failure described by st, typically the
+// status of an error returned by a client, with the category its code maps
+// back to, so IsNotFound and the rest work on the caller side. When several
+// categories share a code the most general one is used, codes without one
+// are System failures. nil is returned for nil and OK statuses.
+func FromGRPCStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	c, ok := grpcCategories[st.Code()]
+	if !ok {
+		c = KindSystem
+	}
+
+	return New(c, "%s", st.Message())
+}
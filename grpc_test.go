@@ -0,0 +1,52 @@
+package failure_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCCode(t *testing.T) {
+	assert.Equal(t, codes.NotFound, failure.GRPCCode(failure.NotFound("user")))
+	assert.Equal(t, codes.AlreadyExists, failure.GRPCCode(failure.AlreadyExists("user")))
+	assert.Equal(t, codes.InvalidArgument, failure.GRPCCode(failure.Validation("email")))
+	assert.Equal(t, codes.DeadlineExceeded, failure.GRPCCode(failure.Timeout("db")))
+	assert.Equal(t, codes.Unknown, failure.GRPCCode(errors.New("raw")))
+	assert.Equal(t, codes.OK, failure.GRPCCode(nil))
+}
+
+func TestFromGRPCStatus(t *testing.T) {
+	for _, err := range []error{
+		failure.NotFound("user"),
+		failure.AlreadyExists("user"),
+		failure.OutOfRange("page"),
+		failure.Timeout("db"),
+		failure.NotAuthenticated("token"),
+	} {
+		back := failure.FromGRPCStatus(status.New(failure.GRPCCode(err), err.Error()))
+		require.Error(t, back)
+
+		var want, got failure.Category
+		require.True(t, errors.As(err, &want))
+		require.True(t, errors.As(back, &got))
+		assert.Equal(t, want, got)
+		assert.True(t, strings.HasPrefix(back.Error(), err.Error()))
+	}
+
+	assert.True(t, failure.IsSystem(failure.FromGRPCStatus(status.New(codes.DataLoss, "disk"))))
+	assert.True(t, failure.IsConflict(failure.FromGRPCStatus(status.New(codes.Aborted, "version"))))
+	assert.Equal(t, codes.Aborted, failure.GRPCCode(failure.Conflict("version")))
+	assert.True(t, failure.IsUnavailable(failure.FromGRPCStatus(status.New(codes.Unavailable, "billing"))))
+	assert.True(t, failure.IsNotImplemented(failure.FromGRPCStatus(status.New(codes.Unimplemented, "export"))))
+	assert.Equal(t, codes.Unavailable, failure.GRPCCode(failure.Unavailable("billing")))
+	assert.Equal(t, codes.Unimplemented, failure.GRPCCode(failure.NotImplemented("export")))
+	assert.True(t, failure.IsResourceExhausted(failure.FromGRPCStatus(status.New(codes.ResourceExhausted, "quota"))))
+	assert.NoError(t, failure.FromGRPCStatus(status.New(codes.OK, "")))
+	assert.NoError(t, failure.FromGRPCStatus(nil))
+}
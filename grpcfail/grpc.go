@@ -0,0 +1,43 @@
+// Package grpcfail integrates failures with gRPC servers and clients.
+package grpcfail
+
+import (
+	"context"
+
+	"github.com/rsb/failure"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// internalMsg is the status message sent for recovered panics, the panic
+// itself is only reported, never sent to the client.
+const internalMsg = "internal error"
+
+// UnaryServerInterceptor recovers panics in unary handlers, converting them
+// into Panic failures that are sent to failure.Report, and answers with
+// codes.Internal.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer recoverPanic(ctx, &err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming handlers
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(ss.Context(), &err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverPanic(ctx context.Context, errp *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	failure.Report(ctx, failure.FromPanic(v))
+	*errp = status.Error(codes.Internal, internalMsg)
+}
@@ -0,0 +1,70 @@
+package grpcfail_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/rsb/failure/grpcfail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type recorder struct {
+	mutex sync.Mutex
+	errs  []error
+}
+
+func (r *recorder) Report(_ context.Context, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	i := grpcfail.UnaryServerInterceptor()
+	_, err := i(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+		panic("nil map")
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	require.Len(t, rec.errs, 1)
+	assert.True(t, failure.IsPanic(rec.errs[0]))
+	_, ok := failure.StackTrace(rec.errs[0])
+	assert.True(t, ok)
+
+	resp, err := i(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+type stream struct {
+	grpc.ServerStream
+}
+
+func (stream) Context() context.Context {
+	return context.Background()
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	i := grpcfail.StreamServerInterceptor()
+	err := i(nil, stream{}, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error {
+		panic("closed channel")
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	require.Len(t, rec.errs, 1)
+	assert.True(t, failure.IsPanic(rec.errs[0]))
+}
@@ -0,0 +1,75 @@
+package failure
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Headers carrying the classification of a failure between services, so
+// callers can rebuild it even when the body is opaque or streamed.
+const (
+	HeaderCategory  = "X-Failure-Category"
+	HeaderRetryable = "X-Failure-Retryable"
+	HeaderCode      = "X-Failure-Code"
+	HeaderStatus    = "X-Failure-Status"
+)
+
+// StatusDetail is the detail holding the status code read by FromHeaders
+const StatusDetail = "http.status"
+
+// SetHeaders sets the failure headers describing e on h: its category,
+// whether it is retryable, its code, see WithCode, and the status code it
+// maps to. The category and code headers are left out when e has none.
+// WriteError calls it for every response.
+func SetHeaders(h http.Header, e error) {
+	if e == nil {
+		return
+	}
+
+	if c, ok := Kind(e); ok {
+		h.Set(HeaderCategory, c.String())
+	}
+	h.Set(HeaderRetryable, strconv.FormatBool(IsRetryable(e)))
+	if code, ok := Code(e); ok {
+		h.Set(HeaderCode, code)
+	}
+	h.Set(HeaderStatus, strconv.Itoa(HTTPStatus(e)))
+}
+
+// FromHeaders rebuilds the failure described by the headers h, typically
+// those of a response from another service, wrapped with the message. The
+// result has the category of the header, keeps the retryable flag the
+// sender computed and its code, and carries the status code as
+// StatusDetail. nil is returned when h has no category header.
+//
+//	if resp.StatusCode >= 400 {
+//		if err := failure.FromHeaders(resp.Header, "GET %s", url); err != nil {
+//			return err
+//		}
+//	}
+func FromHeaders(h http.Header, format string, a ...interface{}) error {
+	c := h.Get(HeaderCategory)
+	if c == "" {
+		return nil
+	}
+
+	err := Wrap(Category(c), format, a...)
+
+	if v, perr := strconv.ParseBool(h.Get(HeaderRetryable)); perr == nil {
+		if v {
+			err = MarkRetryable(err)
+		} else {
+			err = MarkPermanent(err)
+		}
+	}
+
+	if code := h.Get(HeaderCode); code != "" {
+		err = WithCode(err, code)
+	}
+
+	if status, perr := strconv.Atoi(h.Get(HeaderStatus)); perr == nil {
+		err = WithDetail(err, StatusDetail, status)
+	}
+
+	return err
+}
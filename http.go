@@ -0,0 +1,239 @@
+package failure
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrorResponse is the JSON envelope written to clients for a failure
+type ErrorResponse struct {
+	Status   int               `json:"status"`
+	Category string            `json:"category,omitempty"`
+	Code     string            `json:"code,omitempty"`
+	Message  string            `json:"message"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	// Key and Errors describe a Catalog, Errors holds the failed fields
+	// by group
+	Key    string                       `json:"key,omitempty"`
+	Errors map[string]map[string]string `json:"errors,omitempty"`
+}
+
+// NewErrorResponse builds the envelope for e. Server errors only expose the
+// status text so internal details never reach clients, unless e carries a
+// message meant for them, see WithPublicMessage.
+func NewErrorResponse(e error) ErrorResponse {
+	status := HTTPStatus(e)
+	resp := ErrorResponse{
+		Status:  status,
+		Message: http.StatusText(status),
+	}
+
+	if c, ok := Kind(e); ok {
+		resp.Category = c.String()
+	}
+	resp.Code, _ = Code(e)
+
+	var r *RestAPI
+	if errors.As(e, &r) {
+		resp.Fields = r.Fields
+		if status < http.StatusInternalServerError && r.Msg != "" {
+			resp.Message, _ = truncate(r.Msg)
+		}
+	}
+
+	var c *Catalog
+	if errors.As(e, &c) {
+		resp.Key = c.Key
+		resp.Errors = c.AllFailures()
+		if status < http.StatusInternalServerError {
+			resp.Message = KindInvalidAPIFields.Error()
+		}
+	}
+
+	if msg, ok := PublicMessage(e); ok {
+		resp.Message, _ = truncate(msg)
+	}
+
+	return resp
+}
+
+// HTTPStatus maps e to the status of its HTTP response. The status of a
+// RestAPI or a Catalog wins, otherwise the category decides, NotFound is a
+// 404, Validation a 422, Timeout a 504 and so on, see Taxonomy for the full
+// table. Errors without a category are a 500 and nil is a 200.
+func HTTPStatus(e error) int {
+	if e == nil {
+		return http.StatusOK
+	}
+
+	if code, ok := RestStatusCode(e); ok {
+		return code
+	}
+
+	var c *Catalog
+	if errors.As(e, &c) && c.Status != 0 {
+		return c.Status
+	}
+
+	if c, ok := Kind(e); ok {
+		if subject, _ := ExpiredSubject(e); c == KindExpired && subject == ExpiredToken {
+			return http.StatusUnauthorized
+		}
+		if info, ok := categoryInfoOf(c); ok {
+			return info.status
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Is4xx reports whether e is answered with a client error status, taking
+// both RestAPI status codes and the status of its category into account.
+func Is4xx(e error) bool {
+	status := HTTPStatus(e)
+	return status >= 400 && status < 500
+}
+
+// Is5xx reports whether e is answered with a server error status, errors
+// that are not failures count as one.
+func Is5xx(e error) bool {
+	return HTTPStatus(e) >= 500
+}
+
+// WriteError writes the envelope of e as the response, with the status
+// picked from e, the failure headers, see SetHeaders, the Headers of a
+// RestAPI and a Retry-After header when e suggests a wait, so handlers end
+// with a single call:
+//
+//	if err := svc.CreateUser(ctx, in); err != nil {
+//		failure.WriteError(w, err)
+//		return
+//	}
+func WriteError(w http.ResponseWriter, e error) {
+	resp := NewErrorResponse(e)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	setResponseHeaders(w.Header(), e)
+	w.WriteHeader(resp.Status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// WriteCatalog writes c as the response with the catalog status and its
+// failed fields grouped in the envelope, so validation handlers end with a
+// single call:
+//
+//	if c.ErrorCount() > 0 {
+//		failure.WriteCatalog(w, c)
+//		return
+//	}
+func WriteCatalog(w http.ResponseWriter, c *Catalog) {
+	WriteError(w, c)
+}
+
+// setResponseHeaders sets the headers of the response written for e: the
+// Headers of a RestAPI, the failure headers and Retry-After.
+func setResponseHeaders(h http.Header, e error) {
+	var r *RestAPI
+	if errors.As(e, &r) {
+		for k, v := range r.Headers {
+			h[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+
+	SetHeaders(h, e)
+	if d, ok := RetryAfter(e); ok {
+		h.Set("Retry-After", retryAfterSeconds(d))
+	}
+}
+
+// retryAfterSeconds renders d as the whole number of seconds the
+// Retry-After header expects, rounding up so clients never retry early.
+func retryAfterSeconds(d time.Duration) string {
+	secs := int64((d + time.Second - 1) / time.Second)
+	if secs < 0 {
+		secs = 0
+	}
+
+	return strconv.FormatInt(secs, 10)
+}
+
+// Recover is net/http middleware that recovers panics in next, converts
+// them into Panic failures carrying the stack trace, sends them to Report
+// and answers with a 500 unless the handler already started its response.
+// http.ErrAbortHandler is re-panicked as net/http expects.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseRecorder{ResponseWriter: w}
+
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			respond(rw, r, FromPanic(v))
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// Handler adapts a handler returning an error into an http.Handler, making
+// this package the error layer of a net/http service. Returned failures are
+// sent to Report and answered with WriteError, the status coming from the
+// category and Catalog failures listing their invalid fields, unless fn
+// already started the response. Soft failures, see MarkSoft, are answered
+// the same way: a handler degrading gracefully absorbs them with Degrade
+// rather than returning them. Panics are handled as in Recover.
+//
+//	mux.Handle("/users", failure.Handler(func(w http.ResponseWriter, r *http.Request) error {
+//		u, err := svc.User(r.Context(), r.URL.Query().Get("id"))
+//		if err != nil {
+//			return err
+//		}
+//		return json.NewEncoder(w).Encode(u)
+//	}))
+func Handler(fn func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseRecorder{ResponseWriter: w}
+
+		if err := fn(rw, r); err != nil {
+			respond(rw, r, err)
+		}
+	}))
+}
+
+// respond reports err and answers with it unless the response was started
+func respond(rw *responseRecorder, r *http.Request, err error) {
+	Report(r.Context(), err)
+	if !rw.written {
+		WriteError(rw, err)
+	}
+}
+
+// responseRecorder remembers whether the response has been started
+type responseRecorder struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.written = true
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.written = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the original writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
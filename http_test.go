@@ -0,0 +1,323 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewErrorResponse(t *testing.T) {
+	resp := failure.NewErrorResponse(failure.BadRequest("missing id"))
+	assert.Equal(t, http.StatusBadRequest, resp.Status)
+	assert.Equal(t, "bad_request", resp.Category)
+	assert.Equal(t, "missing id", resp.Message)
+
+	fields := map[string]string{"name": "is required"}
+	resp = failure.NewErrorResponse(failure.InvalidFields(fields, "invalid user"))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Status)
+	assert.Equal(t, fields, resp.Fields)
+
+	resp = failure.NewErrorResponse(failure.System("dsn user:secret@db"))
+	assert.Equal(t, http.StatusInternalServerError, resp.Status)
+	assert.Equal(t, "system", resp.Category)
+	assert.Equal(t, "Internal Server Error", resp.Message)
+}
+
+func TestRecover(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	h := failure.Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("nil map")
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var body failure.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "panic", body.Category)
+
+	reported := rec.reported()
+	require.Len(t, reported, 1)
+	assert.True(t, failure.IsPanic(reported[0]))
+	assert.Contains(t, reported[0].Error(), "nil map")
+	_, ok := failure.StackTrace(reported[0])
+	assert.True(t, ok)
+}
+
+func TestRecover_ErrorValue(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	cause := errors.New("boom")
+	h := failure.Recover(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic(cause)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	body, _ := io.ReadAll(w.Body)
+	assert.Empty(t, body)
+
+	reported := rec.reported()
+	require.Len(t, reported, 1)
+	assert.True(t, errors.Is(reported[0], cause))
+	assert.True(t, failure.IsPanic(reported[0]))
+}
+
+func TestRecover_Soft(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	soft := failure.MarkSoft(failure.System("cache down"))
+	h := failure.Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(soft)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotEmpty(t, w.Body.Bytes())
+
+	reported := rec.reported()
+	require.Len(t, reported, 1)
+	assert.True(t, errors.Is(reported[0], soft))
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	h := failure.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestRecover_AbortHandler(t *testing.T) {
+	h := failure.Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+	failure.WriteError(w, failure.NewOverloaded(1500*time.Millisecond, 100, "shedding"))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	var body failure.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "overloaded", body.Category)
+	assert.Equal(t, "Service Unavailable", body.Message)
+
+	w = httptest.NewRecorder()
+	failure.WriteError(w, failure.NotFound("user"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestWriteError_TooManyRequests(t *testing.T) {
+	r := failure.NewTooManyRequests(30*time.Second, "slow down")
+	r.Headers = http.Header{"x-ratelimit-remaining": {"0"}}
+
+	err := failure.Wrap(r, "create user")
+	assert.True(t, failure.IsTooManyRequests(err))
+	d, ok := failure.RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	w := httptest.NewRecorder()
+	failure.WriteError(w, err)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("X-Ratelimit-Remaining"))
+
+	var body failure.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "slow down", body.Message)
+
+	w = httptest.NewRecorder()
+	failure.WriteProblem(w, err)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("X-Ratelimit-Remaining"))
+}
+
+func TestWriteCatalog(t *testing.T) {
+	c := failure.NewCatalog("create_user", http.StatusBadRequest)
+	c.AddField("body", "email", "is required")
+
+	w := httptest.NewRecorder()
+	failure.WriteCatalog(w, c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body failure.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "create_user", body.Key)
+	assert.Equal(t, "invalid_api_fields", body.Category)
+	assert.Equal(t, failure.InvalidAPIFieldsMsg, body.Message)
+	assert.Equal(t, map[string]map[string]string{"body": {"email": "is required"}}, body.Errors)
+
+	w = httptest.NewRecorder()
+	failure.WriteError(w, failure.Wrap(failure.NewCatalog("x", 0), "validate"))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestNewErrorResponse_Expired(t *testing.T) {
+	at := time.Now()
+
+	resp := failure.NewErrorResponse(failure.NewExpired(failure.ExpiredToken, at, "session"))
+	assert.Equal(t, http.StatusUnauthorized, resp.Status)
+
+	resp = failure.NewErrorResponse(failure.NewExpired(failure.ExpiredLink, at, "invite"))
+	assert.Equal(t, http.StatusGone, resp.Status)
+
+	resp = failure.NewErrorResponse(failure.Expired("invite"))
+	assert.Equal(t, http.StatusGone, resp.Status)
+	assert.Equal(t, "expired", resp.Category)
+}
+
+func TestFailureHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	failure.WriteError(w, failure.Timeout("upstream"))
+	assert.Equal(t, "timeout", w.Header().Get(failure.HeaderCategory))
+	assert.Equal(t, "true", w.Header().Get(failure.HeaderRetryable))
+	assert.Equal(t, "504", w.Header().Get(failure.HeaderStatus))
+	assert.Empty(t, w.Header().Get(failure.HeaderCode))
+
+	err := failure.FromHeaders(w.Header(), "GET %s", "/users")
+	require.Error(t, err)
+	assert.True(t, failure.IsTimeout(err))
+	assert.True(t, failure.IsRetryable(err))
+	status, _ := failure.Detail(err, failure.StatusDetail)
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+
+	h := http.Header{}
+	failure.SetHeaders(h, failure.MarkPermanent(failure.Timeout("upstream")))
+	assert.False(t, failure.IsRetryable(failure.FromHeaders(h, "GET")))
+
+	h = http.Header{}
+	failure.SetHeaders(h, failure.WithCode(failure.NotFound("user"), "USER_NOT_FOUND"))
+	assert.Equal(t, "USER_NOT_FOUND", h.Get(failure.HeaderCode))
+	code, ok := failure.Code(failure.FromHeaders(h, "GET"))
+	assert.True(t, ok)
+	assert.Equal(t, "USER_NOT_FOUND", code)
+
+	assert.NoError(t, failure.FromHeaders(http.Header{}, "GET"))
+}
+
+func TestStatusClass(t *testing.T) {
+	assert.True(t, failure.Is4xx(failure.NotFound("user")))
+	assert.True(t, failure.Is4xx(failure.BadRequest("missing id")))
+	assert.True(t, failure.Is4xx(failure.Canceled("client left")))
+	assert.False(t, failure.Is5xx(failure.NotFound("user")))
+
+	assert.True(t, failure.Is5xx(failure.System("db")))
+	assert.True(t, failure.Is5xx(failure.Timeout("upstream")))
+	assert.True(t, failure.Is5xx(errors.New("raw")))
+	assert.False(t, failure.Is4xx(failure.System("db")))
+
+	assert.False(t, failure.Is4xx(nil))
+	assert.False(t, failure.Is5xx(nil))
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{failure.NotFound("user"), http.StatusNotFound},
+		{failure.NotAuthorized("admin"), http.StatusForbidden},
+		{failure.Validation("email"), http.StatusUnprocessableEntity},
+		{failure.Timeout("db"), http.StatusGatewayTimeout},
+		{failure.AlreadyExists("user"), http.StatusConflict},
+		{failure.Wrap(failure.NotFound("user"), "load"), http.StatusNotFound},
+		{failure.BadRequest("missing id"), http.StatusBadRequest},
+		{errors.New("raw"), http.StatusInternalServerError},
+		{nil, http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.status, failure.HTTPStatus(tc.err), "%v", tc.err)
+	}
+}
+
+func TestWriteError_Wrapped(t *testing.T) {
+	w := httptest.NewRecorder()
+	failure.WriteError(w, failure.Wrap(failure.AlreadyExists("user"), "create"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var resp failure.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "already_exists", resp.Category)
+}
+
+func TestHandler(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	h := failure.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/catalog":
+			c := failure.NewCatalog("create_user", 0)
+			c.AddField("body", "email", "is required")
+			return c.ErrorOrNil()
+		case "/panic":
+			panic("nil map")
+		case "/system":
+			return failure.System("db is down")
+		case "/soft":
+			return failure.MarkSoft(failure.System("cache down"))
+		}
+		_, err := io.WriteString(w, "ok")
+		return err
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+
+	w = serve("/catalog")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var resp failure.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]map[string]string{"body": {"email": "is required"}}, resp.Errors)
+
+	assert.Equal(t, http.StatusInternalServerError, serve("/system").Code)
+	assert.Equal(t, http.StatusInternalServerError, serve("/panic").Code)
+	w = serve("/soft")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusInternalServerError, resp.Status)
+
+	reported := rec.reported()
+	require.Len(t, reported, 3)
+	assert.True(t, failure.IsSystem(reported[0]))
+	assert.True(t, failure.IsPanic(reported[1]))
+	assert.True(t, failure.IsSoft(reported[2]))
+}
@@ -0,0 +1,120 @@
+package failure
+
+import (
+	"errors"
+	"sync"
+)
+
+// Translator renders the message identified by key in the language lang,
+// such as "fr" or "pt-BR", reporting false when it has no translation. An
+// empty lang asks for the default language.
+type Translator interface {
+	Translate(lang, key string, args ...interface{}) (string, bool)
+}
+
+// TranslatorFunc adapts a function to the Translator interface
+type TranslatorFunc func(lang, key string, args ...interface{}) (string, bool)
+
+// Translate calls fn(lang, key, args...)
+func (fn TranslatorFunc) Translate(lang, key string, args ...interface{}) (string, bool) {
+	return fn(lang, key, args...)
+}
+
+var translation = struct {
+	sync.RWMutex
+	translator Translator
+}{}
+
+// SetTranslator sets the Translator consulted by LocalizedMessage,
+// NewLocalizedErrorResponse and Catalog.AddFieldKey, nil removes it.
+func SetTranslator(t Translator) {
+	translation.Lock()
+	defer translation.Unlock()
+	translation.translator = t
+}
+
+func translate(lang, key string, args []interface{}) (string, bool) {
+	translation.RLock()
+	t := translation.translator
+	translation.RUnlock()
+
+	if t == nil || key == "" {
+		return "", false
+	}
+
+	return t.Translate(lang, key, args...)
+}
+
+type messageKeyKey struct{}
+
+type messageKey struct {
+	key  string
+	args []interface{}
+}
+
+// WithMessageKey returns e carrying the key and arguments of the message
+// shown to clients, translated with the Translator, see LocalizedMessage:
+//
+//	return failure.WithMessageKey(failure.NotFound("order %s", id), "order.missing", id)
+func WithMessageKey(e error, key string, args ...interface{}) error {
+	return annotate(e, messageKeyKey{}, messageKey{key: key, args: args})
+}
+
+// MessageKey returns the key and arguments set with WithMessageKey
+func MessageKey(e error) (string, []interface{}, bool) {
+	v, ok := lookup(e, messageKeyKey{})
+	if !ok {
+		return "", nil, false
+	}
+
+	m := v.(messageKey)
+	return m.key, m.args, true
+}
+
+// LocalizedMessage returns the message of e for clients in the language
+// lang: the translation of its message key when there is one, see
+// WithMessageKey, the message of NewErrorResponse otherwise.
+func LocalizedMessage(e error, lang string) string {
+	if key, args, ok := MessageKey(e); ok {
+		if msg, ok := translate(lang, key, args); ok {
+			return msg
+		}
+	}
+
+	return NewErrorResponse(e).Message
+}
+
+// NewLocalizedErrorResponse is NewErrorResponse with the message and the
+// Catalog fields added with AddFieldKey translated into lang.
+func NewLocalizedErrorResponse(e error, lang string) ErrorResponse {
+	resp := NewErrorResponse(e)
+	resp.Message = LocalizedMessage(e, lang)
+
+	var c *Catalog
+	if !errors.As(e, &c) {
+		return resp
+	}
+
+	for _, g := range c.fieldsByGroup() {
+		for _, f := range g.fields {
+			if msg, ok := translate(lang, f.key, f.args); ok {
+				resp.Errors[g.name][f.Name] = msg
+			}
+		}
+	}
+
+	return resp
+}
+
+// AddFieldKey records that field of group failed with the message
+// identified by key, see AddField. Msg holds its translation into the
+// default language, or key when there is none, and
+// NewLocalizedErrorResponse translates it for each client.
+func (c *Catalog) AddFieldKey(group, field, key string, args ...interface{}) {
+	msg, ok := translate("", key, args)
+	if !ok {
+		msg = key
+	}
+
+	c.setField(group, &Field{Name: field, Msg: msg, key: key, args: args})
+}
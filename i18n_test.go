@@ -0,0 +1,49 @@
+package failure_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+var messages = map[string]map[string]string{
+	"":   {"order.missing": "order %s does not exist", "field.required": "is required"},
+	"fr": {"order.missing": "la commande %s n'existe pas", "field.required": "est obligatoire"},
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	failure.SetTranslator(failure.TranslatorFunc(func(lang, key string, args ...interface{}) (string, bool) {
+		msg, ok := messages[lang][key]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf(msg, args...), true
+	}))
+	defer failure.SetTranslator(nil)
+
+	err := failure.WithMessageKey(failure.NotFound("order 7"), "order.missing", "7")
+	key, args, ok := failure.MessageKey(err)
+	assert.True(t, ok)
+	assert.Equal(t, "order.missing", key)
+	assert.Equal(t, []interface{}{"7"}, args)
+
+	assert.Equal(t, "la commande 7 n'existe pas", failure.LocalizedMessage(err, "fr"))
+	assert.Equal(t, "order 7 does not exist", failure.LocalizedMessage(err, ""))
+	// no translation falls back to the response message
+	assert.Equal(t, failure.NewErrorResponse(err).Message, failure.LocalizedMessage(err, "de"))
+	assert.Equal(t, http.StatusText(http.StatusInternalServerError), failure.LocalizedMessage(failure.System("db"), "fr"))
+
+	c := failure.NewCatalog("create_user", 0)
+	c.AddFieldKey("body", "email", "field.required")
+	c.AddField("body", "age", "must be at least 18")
+	assert.Equal(t, "is required", c.AllFailures()["body"]["email"])
+
+	resp := failure.NewLocalizedErrorResponse(c, "fr")
+	assert.Equal(t, map[string]map[string]string{
+		"body": {"email": "est obligatoire", "age": "must be at least 18"},
+	}, resp.Errors)
+	assert.Equal(t, "is required", c.AllFailures()["body"]["email"])
+}
@@ -0,0 +1,126 @@
+package failure
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONBodyGroup is the Catalog group of the fields reported by
+// FromJSONDecodeError
+const JSONBodyGroup = "body"
+
+// FromJSONDecodeError maps an error of json.Unmarshal or json.Decoder to a
+// 400 response a client can act on:
+//
+//   - a *json.UnmarshalTypeError becomes a Catalog naming the field, such as
+//     "body.age: must be a number"
+//   - an unknown field, see json.Decoder.DisallowUnknownFields, becomes a
+//     Catalog with "body.name: is not allowed"
+//   - a *json.SyntaxError, a truncated or an empty body becomes a BadRequest
+//   - a body over the limit of http.MaxBytesReader becomes a PayloadTooLarge
+//
+// The Err of the BadRequest wraps the original error. Any other error, such
+// as a failed read of the body, is returned unchanged.
+//
+//	if err := dec.Decode(&in); err != nil {
+//		return failure.FromJSONDecodeError(err)
+//	}
+func FromJSONDecodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return jsonBadRequest(err, "body must be %s", jsonTypeName(typeErr.Type))
+		}
+		return jsonField(typeErr.Field, "must be %s", jsonTypeName(typeErr.Type))
+	}
+
+	if name, ok := unknownJSONField(err); ok {
+		return jsonField(name, "is not allowed")
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return To(KindPayloadTooLarge, err, "body is over %d bytes", tooLarge.Limit)
+	}
+
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return jsonBadRequest(err, "malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return jsonBadRequest(err, "malformed JSON, the body ends early")
+	case errors.Is(err, io.EOF):
+		return jsonBadRequest(err, "body is empty")
+	}
+
+	return err
+}
+
+func jsonField(field, msg string, a ...interface{}) error {
+	c := NewCatalog("", http.StatusBadRequest)
+	c.AddField(JSONBodyGroup, field, msg, a...)
+
+	return captureStack(c)
+}
+
+func jsonBadRequest(err error, msg string, a ...interface{}) error {
+	r := NewBadRequest(msg, a...)
+	r.Err = &classified{err: err, kind: KindBadRequest}
+
+	return captureStack(r)
+}
+
+// unknownJSONField reads the name out of the "json: unknown field" error,
+// encoding/json has no type for it.
+func unknownJSONField(err error) (string, bool) {
+	const prefix = "json: unknown field "
+
+	msg := err.Error()
+	if !strings.HasPrefix(msg, prefix) {
+		return "", false
+	}
+
+	name, uerr := strconv.Unquote(strings.TrimPrefix(msg, prefix))
+	if uerr != nil {
+		return "", false
+	}
+
+	return name, true
+}
+
+// jsonTypeName describes the JSON value expected for t
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "a valid value"
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a valid value"
+	}
+}
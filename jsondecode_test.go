@@ -0,0 +1,74 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSONDecodeError(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+		Tags []string
+	}
+
+	decode := func(body string) error {
+		dec := json.NewDecoder(strings.NewReader(body))
+		dec.DisallowUnknownFields()
+		var u user
+		return failure.FromJSONDecodeError(dec.Decode(&u))
+	}
+
+	tests := []struct {
+		body   string
+		fields map[string]map[string]string
+		msg    string
+	}{
+		{body: `{"age":"old"}`, fields: map[string]map[string]string{"body": {"age": "must be a number"}}},
+		{body: `{"Tags":"a"}`, fields: map[string]map[string]string{"body": {"Tags": "must be an array"}}},
+		{body: `{"admin":true}`, fields: map[string]map[string]string{"body": {"admin": "is not allowed"}}},
+		{body: `[1]`, msg: "body must be an object"},
+		{body: `{"name":}`, msg: "malformed JSON at offset 9"},
+		{body: `{"name":"a"`, msg: "malformed JSON, the body ends early"},
+		{body: ``, msg: "body is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			err := decode(tt.body)
+			require.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, failure.HTTPStatus(err))
+
+			if tt.fields != nil {
+				var c *failure.Catalog
+				require.True(t, errors.As(err, &c))
+				assert.Equal(t, tt.fields, c.AllFailures())
+				return
+			}
+
+			assert.True(t, failure.IsBadRequest(err))
+			assert.Equal(t, tt.msg, failure.NewErrorResponse(err).Message)
+		})
+	}
+
+	var r *failure.RestAPI
+	require.True(t, errors.As(decode(`{"name":}`), &r))
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(r.Err, &syntaxErr))
+
+	body := http.MaxBytesReader(nil, io.NopCloser(strings.NewReader(`{"name":"abcdef"}`)), 4)
+	err := failure.FromJSONDecodeError(json.NewDecoder(body).Decode(&struct{}{}))
+	assert.True(t, failure.IsPayloadTooLarge(err))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, failure.HTTPStatus(err))
+
+	assert.NoError(t, failure.FromJSONDecodeError(nil))
+	assert.Equal(t, io.ErrClosedPipe, failure.FromJSONDecodeError(io.ErrClosedPipe))
+}
@@ -0,0 +1,123 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"google.golang.org/grpc/codes"
+)
+
+// CustomKind is a category created by an application with NewKind, with
+// the constructor and checks the built in categories have as functions.
+type CustomKind struct {
+	kind Category
+}
+
+// KindOption configures a category created with NewKind
+type KindOption func(*categoryInfo)
+
+// KindStatus sets the HTTP response status of the category, it defaults to
+// 500.
+func KindStatus(status int) KindOption {
+	return func(info *categoryInfo) {
+		info.status = status
+	}
+}
+
+// KindSeverity sets the default severity of the category, it defaults to
+// SeverityError.
+func KindSeverity(s Severity) KindOption {
+	return func(info *categoryInfo) {
+		info.severity = s
+	}
+}
+
+// KindRetryable makes the failures of the category retryable by default
+func KindRetryable() KindOption {
+	return func(info *categoryInfo) {
+		info.retryable = true
+	}
+}
+
+// NewKind creates a category for a failure of your domain that doesn't map
+// to a built in one. msg is its canonical message and its name is msg in
+// snake case, "quota exceeded" is named quota_exceeded:
+//
+//	var QuotaExceeded = failure.NewKind("quota exceeded",
+//		failure.KindStatus(http.StatusTooManyRequests),
+//		failure.KindSeverity(failure.SeverityWarning))
+//
+//	return QuotaExceeded.New("tenant %s used %d calls", id, n)
+//
+//	if QuotaExceeded.Is(err) {
+//
+// The category takes part in everything the built in ones do, Kind,
+// HTTPStatus, SeverityOf, Taxonomy and so on. NewKind is meant to be
+// called when declaring package variables, it panics when the name is
+// already taken.
+func NewKind(msg string, opts ...KindOption) CustomKind {
+	c := Category(kindName(msg))
+	info := categoryInfo{
+		msg:      msg,
+		severity: SeverityError,
+		status:   http.StatusInternalServerError,
+		grpc:     codes.Unknown,
+	}
+	for _, opt := range opts {
+		opt(&info)
+	}
+
+	if !registerCategory(c, info) {
+		panic(fmt.Sprintf("failure: category %q already exists", string(c)))
+	}
+
+	return CustomKind{kind: c}
+}
+
+// registerCategory adds c to the custom categories, reporting false when
+// the name is empty or already taken.
+func registerCategory(c Category, info categoryInfo) bool {
+	customCategories.Lock()
+	defer customCategories.Unlock()
+
+	_, builtin := categories[c]
+	_, custom := customCategories.infos[c]
+	if c == "" || builtin || custom {
+		return false
+	}
+	customCategories.infos[c] = info
+
+	return true
+}
+
+// kindName turns a message into a category name
+func kindName(msg string) string {
+	words := strings.FieldsFunc(strings.ToLower(msg), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "_")
+}
+
+// Category returns the category of k, for switches over Kind
+func (k CustomKind) Category() Category {
+	return k.kind
+}
+
+// New creates a failure of the category with the message
+func (k CustomKind) New(format string, a ...interface{}) error {
+	return New(k.kind, format, a...)
+}
+
+// Is reports whether e is a failure of the category
+func (k CustomKind) Is(e error) bool {
+	return errors.Is(e, k.kind)
+}
+
+// To classifies e as a failure of the category, see To
+func (k CustomKind) To(e error, format string, a ...interface{}) error {
+	return To(k.kind, e, format, a...)
+}
@@ -0,0 +1,103 @@
+package failure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// LambdaHandler wraps an AWS Lambda handler so every function handles its
+// failures the same way:
+//
+//	lambda.Start(failure.LambdaHandler(handle))
+//
+// Panics in fn are recovered into Panic failures. Returned errors are
+// classified, a Timeout when the invocation deadline has passed, Canceled
+// when the context was canceled and System when the error carries no
+// category, then sent to Report.
+//
+// When Out is an API Gateway response, such as
+// events.APIGatewayProxyResponse or events.APIGatewayV2HTTPResponse, the
+// failure is answered with the mapped response instead, see WriteError,
+// and no error is returned to the runtime. Any struct with a StatusCode
+// int, a Body string and a Headers map[string]string field is treated as
+// one.
+func LambdaHandler[In, Out any](fn func(ctx context.Context, in In) (Out, error)) func(ctx context.Context, in In) (Out, error) {
+	return func(ctx context.Context, in In) (out Out, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = FromPanic(v)
+			}
+			if err == nil {
+				return
+			}
+
+			err = classifyInvocation(ctx, err)
+			Report(ctx, err)
+
+			if gatewayResponse(&out, err) {
+				err = nil
+			}
+		}()
+
+		return fn(ctx, in)
+	}
+}
+
+// classifyInvocation gives err a category from the state of ctx, or System
+// when it has none.
+func classifyInvocation(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &classified{err: err, kind: KindTimeout, cause: customCause(ctx)}
+	case errors.Is(ctx.Err(), context.Canceled):
+		return &classified{err: err, kind: KindCanceled, cause: customCause(ctx)}
+	}
+
+	if _, ok := Kind(err); !ok {
+		return &classified{err: err, kind: KindSystem}
+	}
+
+	return err
+}
+
+// gatewayResponse fills out with the response for e when it is shaped like
+// an API Gateway response, reporting whether it did.
+func gatewayResponse(out interface{}, e error) bool {
+	v := reflect.ValueOf(out).Elem()
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	status := v.FieldByName("StatusCode")
+	body := v.FieldByName("Body")
+	headers := v.FieldByName("Headers")
+	if status.Kind() != reflect.Int || body.Kind() != reflect.String ||
+		!headers.IsValid() || headers.Type() != reflect.TypeOf(map[string]string(nil)) {
+		return false
+	}
+
+	resp := NewErrorResponse(e)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return false
+	}
+
+	hdr := http.Header{}
+	hdr.Set("Content-Type", "application/json; charset=utf-8")
+	setResponseHeaders(hdr, e)
+
+	h := make(map[string]string, len(hdr))
+	for k := range hdr {
+		h[k] = hdr.Get(k)
+	}
+
+	v.Set(reflect.Zero(v.Type()))
+	status.SetInt(int64(resp.Status))
+	body.SetString(string(data))
+	headers.Set(reflect.ValueOf(h))
+
+	return true
+}
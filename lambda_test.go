@@ -0,0 +1,76 @@
+package failure_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatewayResponse has the shape of events.APIGatewayProxyResponse
+type gatewayResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded,omitempty"`
+}
+
+func TestLambdaHandler(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	h := failure.LambdaHandler(func(_ context.Context, in string) (string, error) {
+		switch in {
+		case "panic":
+			panic("nil map")
+		case "raw":
+			return "", errors.New("boom")
+		}
+		return "ok " + in, nil
+	})
+
+	out, err := h(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Equal(t, "ok x", out)
+
+	_, err = h(context.Background(), "panic")
+	assert.True(t, failure.IsPanic(err))
+
+	_, err = h(context.Background(), "raw")
+	assert.True(t, failure.IsSystem(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = h(ctx, "raw")
+	assert.True(t, failure.IsCanceled(err))
+
+	assert.Len(t, rec.reported(), 2)
+}
+
+func TestLambdaHandler_APIGateway(t *testing.T) {
+	h := failure.LambdaHandler(func(_ context.Context, id string) (gatewayResponse, error) {
+		if id == "" {
+			return gatewayResponse{}, failure.BadRequest("missing id")
+		}
+		return gatewayResponse{StatusCode: http.StatusOK, Body: id}, nil
+	})
+
+	out, err := h(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", out.Body)
+
+	out, err = h(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, out.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", out.Headers["Content-Type"])
+
+	var resp failure.ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(out.Body), &resp))
+	assert.Equal(t, "bad_request", resp.Category)
+	assert.Equal(t, "missing id", resp.Message)
+}
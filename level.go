@@ -0,0 +1,63 @@
+package failure
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// LevelCritical is the slog level of critical failures, slog has no level
+// above error.
+const LevelCritical = slog.LevelError + 4
+
+var levels = struct {
+	sync.RWMutex
+	overrides map[Category]slog.Level
+}{}
+
+// SetCategoryLevels overrides the log level of categories for the whole
+// application, e.g. logging NotFound at debug in one service and info in
+// another. It replaces the previous overrides, nil clears them.
+func SetCategoryLevels(overrides map[Category]slog.Level) {
+	m := make(map[Category]slog.Level, len(overrides))
+	for k, v := range overrides {
+		m[k] = v
+	}
+
+	levels.Lock()
+	defer levels.Unlock()
+	levels.overrides = m
+}
+
+// LevelFor returns the level e should be logged at. A severity set with
+// WithSeverity wins, then the override of its category and finally the
+// level matching the default severity of the category.
+func LevelFor(e error) slog.Level {
+	if _, ok := lookup(e, severityKey{}); !ok {
+		if c, ok := Kind(e); ok {
+			levels.RLock()
+			l, ok := levels.overrides[c]
+			levels.RUnlock()
+			if ok {
+				return downgradeLevel(e, l)
+			}
+		}
+	}
+
+	return SeverityLevel(SeverityOf(e))
+}
+
+// SeverityLevel maps a severity onto a slog level
+func SeverityLevel(s Severity) slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return LevelCritical
+	default:
+		return slog.LevelError
+	}
+}
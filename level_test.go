@@ -0,0 +1,37 @@
+package failure_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelFor(t *testing.T) {
+	assert.Equal(t, slog.LevelWarn, failure.LevelFor(failure.NotFound("user")))
+	assert.Equal(t, slog.LevelError, failure.LevelFor(failure.System("db")))
+	assert.Equal(t, failure.LevelCritical, failure.LevelFor(failure.Panic("nil map")))
+	assert.Equal(t, slog.LevelDebug, failure.LevelFor(failure.Ignore("foo")))
+	assert.Equal(t, slog.LevelError, failure.LevelFor(errors.New("foo")))
+}
+
+func TestSetCategoryLevels(t *testing.T) {
+	defer failure.SetCategoryLevels(nil)
+
+	failure.SetCategoryLevels(map[failure.Category]slog.Level{
+		failure.KindNotFound: slog.LevelDebug,
+	})
+
+	assert.Equal(t, slog.LevelDebug, failure.LevelFor(failure.NotFound("user")))
+	assert.Equal(t, slog.LevelDebug, failure.LevelFor(failure.Wrap(failure.NotFound("user"), "load")))
+	assert.Equal(t, slog.LevelError, failure.LevelFor(failure.System("db")))
+
+	// an explicit severity beats the category override
+	err := failure.WithSeverity(failure.NotFound("config file"), failure.SeverityCritical)
+	assert.Equal(t, failure.LevelCritical, failure.LevelFor(err))
+
+	failure.SetCategoryLevels(nil)
+	assert.Equal(t, slog.LevelWarn, failure.LevelFor(failure.NotFound("user")))
+}
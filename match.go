@@ -0,0 +1,131 @@
+package failure
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// RegexpPrefix marks a message pattern as a regular expression rather than
+// a glob.
+const RegexpPrefix = "re:"
+
+// Matcher reports whether an error belongs to some class of failures. All
+// the IsX functions in this package are Matchers.
+type Matcher func(error) bool
+
+// AnyOf returns a Matcher that matches when at least one of ms matches
+func AnyOf(ms ...Matcher) Matcher {
+	return func(e error) bool {
+		for _, m := range ms {
+			if m(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllOf returns a Matcher that matches when every one of ms matches
+func AllOf(ms ...Matcher) Matcher {
+	return func(e error) bool {
+		for _, m := range ms {
+			if !m(e) {
+				return false
+			}
+		}
+		return len(ms) > 0
+	}
+}
+
+// Not returns a Matcher that matches when m does not
+func Not(m Matcher) Matcher {
+	return func(e error) bool {
+		return !m(e)
+	}
+}
+
+// IsAny reports whether err matches any of kinds with errors.Is, kinds
+// being categories such as KindTimeout or any other sentinel:
+//
+//	if failure.IsAny(err, failure.KindTimeout, failure.KindServer, failure.KindShutdown) {
+//
+// It keeps retry policies built from a slice of sentinels short.
+func IsAny(err error, kinds ...error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, k := range kinds {
+		if errors.Is(err, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchMessage reports whether the message of err, or of any error it wraps,
+// matches pattern. Patterns are globs where `*` matches any run of
+// characters and `?` a single one, unless prefixed with RegexpPrefix in which
+// case the rest is a regular expression. An invalid pattern matches nothing.
+//
+// This is a stopgap for errors from libraries that can't be classified by
+// type or sentinel, prefer the IsX functions whenever possible.
+func MatchMessage(err error, pattern string) bool {
+	re, e := compileMessagePattern(pattern)
+	if e != nil {
+		return false
+	}
+
+	return matchMessage(err, re)
+}
+
+// MessageMatcher is the Matcher form of MatchMessage. The pattern is compiled
+// once, it panics if the pattern is invalid.
+func MessageMatcher(pattern string) Matcher {
+	re, err := compileMessagePattern(pattern)
+	if err != nil {
+		panic(InvalidParam("invalid message pattern (%s): %v", pattern, err))
+	}
+
+	return func(e error) bool {
+		return matchMessage(e, re)
+	}
+}
+
+func matchMessage(err error, re *regexp.Regexp) bool {
+	if err == nil {
+		return false
+	}
+
+	var found bool
+	Walk(err, func(e error) bool {
+		found = re.MatchString(e.Error())
+		return !found
+	})
+
+	return found
+}
+
+func compileMessagePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, RegexpPrefix) {
+		return regexp.Compile(strings.TrimPrefix(pattern, RegexpPrefix))
+	}
+
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,61 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchMessage(t *testing.T) {
+	api := errors.New("read tcp 10.0.0.1:5432: connection reset by peer")
+	err := failure.ToSystem(api, "db.Query failed")
+
+	assert.True(t, failure.MatchMessage(err, "*connection reset*"))
+	assert.True(t, failure.MatchMessage(err, "read tcp ?0.0.0.1:*"))
+	assert.True(t, failure.MatchMessage(err, "re:connection (reset|refused)"))
+	assert.False(t, failure.MatchMessage(err, "connection reset"))
+	assert.False(t, failure.MatchMessage(err, "*broken pipe*"))
+	assert.False(t, failure.MatchMessage(err, "re:(unclosed"))
+	assert.False(t, failure.MatchMessage(nil, "*"))
+}
+
+func TestMatchMessage_Multi(t *testing.T) {
+	err := failure.Multiple([]error{
+		errors.New("foo"),
+		failure.Wrap(errors.New("broken pipe"), "write"),
+	})
+
+	assert.True(t, failure.MatchMessage(err, "broken pipe"))
+}
+
+func TestMessageMatcher(t *testing.T) {
+	m := failure.MessageMatcher("*reset by peer*")
+	err := errors.New("connection reset by peer")
+
+	assert.True(t, m(err))
+	assert.False(t, m(errors.New("foo")))
+
+	retryable := failure.AnyOf(failure.IsTimeout, m)
+	assert.True(t, retryable(err))
+	assert.True(t, retryable(failure.Timeout("slow")))
+	assert.False(t, retryable(failure.System("foo")))
+
+	both := failure.AllOf(failure.IsSystem, failure.Not(m))
+	assert.True(t, both(failure.System("foo")))
+	assert.False(t, both(failure.ToSystem(err, "foo")))
+
+	assert.Panics(t, func() { failure.MessageMatcher("re:(unclosed") })
+}
+
+func TestIsAny(t *testing.T) {
+	err := failure.Wrap(failure.Timeout("db"), "load user")
+	assert.True(t, failure.IsAny(err, failure.KindServer, failure.KindTimeout))
+	assert.False(t, failure.IsAny(err, failure.KindServer, failure.KindShutdown))
+	assert.False(t, failure.IsAny(err))
+	assert.False(t, failure.IsAny(nil, failure.KindTimeout))
+
+	sentinel := errors.New("eof")
+	assert.True(t, failure.IsAny(failure.To(failure.KindSystem, sentinel, "read"), sentinel))
+}
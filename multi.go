@@ -1,15 +1,26 @@
 package failure
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Multi struct {
 	Failures  []error
 	Formatter MultiFormatFn
+	// occurred holds when each failure was appended, by index, the zero
+	// time when unknown. It can be shorter than Failures.
+	occurred []time.Time
+	// limit bounds the failures stored, zero keeps them all, see SetLimit
+	limit     int
+	truncated int
 }
 
 func (e *Multi) Error() string {
@@ -18,7 +29,51 @@ func (e *Multi) Error() string {
 		fn = ListFormatFn
 	}
 
-	return fn(e.Failures)
+	s := fn(e.Failures)
+	if e.truncated > 0 {
+		s = fmt.Sprintf("%s\t… and %d more\n\n", strings.TrimRight(s, "\n")+"\n", e.truncated)
+	}
+
+	s, _ = truncate(s)
+	return s
+}
+
+// SetLimit bounds the failures e stores to n, a batch of a million items
+// can't accumulate a million failures:
+//
+//	result := new(failure.Multi)
+//	result.SetLimit(100)
+//	for _, item := range items {
+//		if err := process(item); err != nil {
+//			result = failure.Append(result, err)
+//		}
+//	}
+//
+// Failures appended past the limit are only counted, see Truncated, and
+// the message ends with "… and 42 more". Failures e already holds past n
+// are dropped the same way. Zero or less removes the limit.
+func (e *Multi) SetLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	e.limit = n
+
+	if n > 0 && len(e.Failures) > n {
+		e.truncated += len(e.Failures) - n
+		e.Failures = e.Failures[:n:n]
+		if len(e.occurred) > n {
+			e.occurred = e.occurred[:n:n]
+		}
+	}
+}
+
+// Truncated returns the number of failures that were not stored because of
+// the limit, see SetLimit.
+func (e *Multi) Truncated() int {
+	if e == nil {
+		return 0
+	}
+	return e.truncated
 }
 
 // ErrorOrNil returns an error interface if this Error represents
@@ -36,6 +91,39 @@ func (e *Multi) ErrorOrNil() error {
 	return e
 }
 
+// ErrorOrNilExcluding is ErrorOrNil ignoring the failures matched by any of
+// checks, so a batch that only produced ignorable failures counts as a
+// success. Members of nested Multi values are checked one by one. e itself
+// is returned untouched, with every failure, when something is left.
+//
+//	if err := result.ErrorOrNilExcluding(failure.IsWarn, failure.IsIgnore); err != nil {
+//		return err
+//	}
+func (e *Multi) ErrorOrNilExcluding(checks ...Matcher) error {
+	if e == nil || !hasRemaining(e.Failures, AnyOf(checks...)) {
+		return nil
+	}
+
+	return e
+}
+
+// hasRemaining reports whether any of es is not excluded
+func hasRemaining(es []error, excluded Matcher) bool {
+	for _, err := range es {
+		if m, ok := err.(*Multi); ok {
+			if hasRemaining(m.Failures, excluded) {
+				return true
+			}
+			continue
+		}
+		if err != nil && !excluded(err) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // WrappedErrors returns the list of errors that this Error is wrapping. It is
 // an implementation of the errwrap.Wrapper interface so that failure.Multi
 // can be used with that library.
@@ -78,6 +166,38 @@ func (e *Multi) Unwrap() error {
 	return chain(errs)
 }
 
+// Is reports whether any failure of e matches target, see errors.Is, however
+// deep it is wrapped, without going through the chain Unwrap returns.
+func (e *Multi) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+
+	for _, f := range e.Failures {
+		if f != nil && errors.Is(f, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first failure of e that matches target, see errors.As,
+// however deep it is wrapped, and sets target to it.
+func (e *Multi) As(target interface{}) bool {
+	if e == nil {
+		return false
+	}
+
+	for _, f := range e.Failures {
+		if f != nil && errors.As(f, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // chain implements the interfaces necessary for errors.Is/As/Unwrap to
 // work in a deterministic way with multierror. A chain tracks a list of
 // errors while accounting for the current represented error. This lets
@@ -116,6 +236,15 @@ func (e chain) Is(target error) bool {
 	return errors.Is(e[0], target)
 }
 
+// Append is a helper function that will append more errors onto a Multi in
+// order to create a larger multi-error. If err is not a *Multi, it is turned
+// into one. Any *Multi found in errs is flattened into the result and nil
+// errors are skipped, matching hashicorp/go-multierror so the two can be
+// swapped without changing behavior. Errors built by errors.Join, or by
+// another multi-error package exposing WrappedErrors, are flattened into
+// their members as well. The failures are stored as given,
+// the time each one was added is kept alongside, see OccurredAt. Past the
+// limit of err, see SetLimit, failures are only counted.
 func Append(err error, errs ...error) *Multi {
 	switch err := err.(type) {
 	case *Multi:
@@ -125,17 +254,9 @@ func Append(err error, errs ...error) *Multi {
 		}
 
 		// flat each error
+		now := time.Now()
 		for _, e := range errs {
-			switch e := e.(type) {
-			case *Multi:
-				if e != nil {
-					err.Failures = append(err.Failures, e.Failures...)
-				}
-			default:
-				if e != nil {
-					err.Failures = append(err.Failures, e)
-				}
-			}
+			err.addAll(e, now)
 		}
 		return err
 	default:
@@ -148,6 +269,82 @@ func Append(err error, errs ...error) *Multi {
 	}
 }
 
+// AppendInto appends err onto the error target points to, in place, and
+// reports whether err was not nil. A nil target error becomes err itself,
+// anything else becomes a Multi as with Append, so failures of deferred
+// calls are kept next to the one being returned:
+//
+//	defer failure.AppendInto(&err, rows.Close())
+//
+// It panics when target is nil.
+func AppendInto(target *error, err error) bool {
+	if target == nil {
+		panic(InvalidParam("failure.AppendInto: target must not be nil"))
+	}
+	if err == nil {
+		return false
+	}
+
+	if *target == nil {
+		*target = err
+	} else {
+		*target = Append(*target, err)
+	}
+
+	return true
+}
+
+// AppendClose closes c and appends its failure onto the error target points
+// to, see AppendInto. Unlike AppendInto it calls Close when deferred rather
+// than when the defer statement runs:
+//
+//	defer failure.AppendClose(&err, f)
+func AppendClose(target *error, c io.Closer) {
+	AppendInto(target, c.Close())
+}
+
+// Prefix is a helper function that will prefix some text to the given
+// error. If the error is a *Multi, then it will be prefixed to each wrapped
+// error. The prefixed errors still unwrap to the originals, so their
+// categories are kept. This is useful to use when appending multiple errors
+// together in order to give better scoping.
+func Prefix(err error, prefix string) error {
+	if err == nil {
+		return nil
+	}
+
+	switch err := err.(type) {
+	case *Multi:
+		// Typed nils can be reached here, so initialize if we are nil
+		if err == nil {
+			err = new(Multi)
+		}
+
+		// Wrap each of the errors
+		for i, e := range err.Failures {
+			err.Failures[i] = &prefixed{prefix: prefix, err: e}
+		}
+
+		return err
+	default:
+		return &prefixed{prefix: prefix, err: err}
+	}
+}
+
+// prefixed is the error built by Prefix
+type prefixed struct {
+	prefix string
+	err    error
+}
+
+func (p *prefixed) Error() string {
+	return p.prefix + " " + p.err.Error()
+}
+
+func (p *prefixed) Unwrap() error {
+	return p.err
+}
+
 func Multiple(errs []error, opt ...MultiFormatFn) *Multi {
 	fn := ListFormatFn
 	if len(opt) > 0 && opt[0] != nil {
@@ -156,23 +353,15 @@ func Multiple(errs []error, opt ...MultiFormatFn) *Multi {
 	return &Multi{Failures: errs, Formatter: fn}
 }
 
+// IsMultiple reports whether e is a Multi or wraps one, at any depth.
 func IsMultiple(e error) bool {
 	var t *Multi
-
-	if errors.As(e, &t) {
-		return true
-	}
-
-	l := errors.Unwrap(e)
-	if errors.As(l, &t) {
-		return true
-	}
-
-	return false
+	return errors.As(e, &t)
 }
 
+// MultiResult returns the failures of the Multi e is or wraps, see AsMulti.
 func MultiResult(e error) ([]error, bool) {
-	err, ok := e.(*Multi)
+	err, ok := AsMulti(e)
 	if !ok {
 		return []error{}, false
 	}
@@ -180,6 +369,23 @@ func MultiResult(e error) ([]error, bool) {
 	return err.Failures, true
 }
 
+// AsMulti returns the outermost Multi in the chain of e, so the failures
+// accumulated by a lower layer can be recovered after being wrapped:
+//
+//	if m, ok := failure.AsMulti(err); ok {
+//		for _, f := range m.Failures {
+//			...
+//		}
+//	}
+func AsMulti(e error) (*Multi, bool) {
+	var m *Multi
+	if !errors.As(e, &m) || m == nil {
+		return nil, false
+	}
+
+	return m, true
+}
+
 // ListFormatFn is a basic formatter that outputs the number of errors
 // that occurred along with a bullet point list of the errors.
 func ListFormatFn(es []error) string {
@@ -199,12 +405,335 @@ func ListFormatFn(es []error) string {
 
 type MultiFormatFn func([]error) string
 
+// Uncategorized is the Summary and ByKind key of failures that carry no
+// category
+const Uncategorized = "uncategorized"
+
+// Summary tallies the failures by category name, nested Multi are counted
+// member by member.
+func (e *Multi) Summary() map[string]int {
+	if e == nil {
+		return map[string]int{}
+	}
+
+	return tally(e.Failures)
+}
+
+// ByKind groups the failures of e by category, in order, so each bucket of
+// a fan-out can be handled on its own. Nested Multi are grouped member by
+// member and failures without a category are under Uncategorized.
+//
+//	for kind, errs := range batch.ByKind() {
+//		metrics.Add(string(kind), len(errs))
+//	}
+func (e *Multi) ByKind() map[Category][]error {
+	groups := map[Category][]error{}
+	if e != nil {
+		groupByKind(e.Failures, groups)
+	}
+
+	return groups
+}
+
+func groupByKind(es []error, groups map[Category][]error) {
+	for _, err := range es {
+		if m, ok := err.(*Multi); ok {
+			if m != nil {
+				groupByKind(m.Failures, groups)
+			}
+			continue
+		}
+		if err == nil {
+			continue
+		}
+
+		c, ok := Kind(err)
+		if !ok {
+			c = Uncategorized
+		}
+		groups[c] = append(groups[c], err)
+	}
+}
+
+// SummaryFormatFn is a formatter that prepends a headline with the category
+// tally, most frequent first, to the bullet point list of the errors:
+//
+//	7 failures: 4 timeout, 2 validation, 1 system
+//		* ...
+func SummaryFormatFn(es []error) string {
+	counts := tally(es)
+
+	names := make([]string, 0, len(counts))
+	total := 0
+	for name, n := range counts {
+		names = append(names, name)
+		total += n
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%d %s", counts[name], name)
+	}
+
+	noun := "failures"
+	if total == 1 {
+		noun = "failure"
+	}
+
+	points := make([]string, len(es))
+	for i, err := range es {
+		points[i] = fmt.Sprintf("* %s", err)
+	}
+
+	return fmt.Sprintf("%d %s: %s\n\t%s\n\n",
+		total, noun, strings.Join(parts, ", "), strings.Join(points, "\n\t"))
+}
+
+func tally(es []error) map[string]int {
+	counts := map[string]int{}
+	for _, err := range es {
+		if m, ok := err.(*Multi); ok {
+			if m != nil {
+				for name, n := range tally(m.Failures) {
+					counts[name] += n
+				}
+			}
+			continue
+		}
+		if err == nil {
+			continue
+		}
+
+		name := Uncategorized
+		if c, ok := Kind(err); ok {
+			name = c.String()
+		}
+		counts[name]++
+	}
+
+	return counts
+}
+
+// addAll adds the members of f when it is a Multi or another multi-error,
+// see members, and f itself otherwise. Failures without a time of their own
+// are given at.
+func (e *Multi) addAll(f error, at time.Time) {
+	if m, ok := f.(*Multi); ok {
+		if m != nil {
+			for i, g := range m.Failures {
+				t, _ := m.OccurredAt(i)
+				e.add(g, t)
+			}
+			e.truncated += m.truncated
+		}
+		return
+	}
+
+	if fs, ok := members(f); ok {
+		for _, g := range fs {
+			e.addAll(g, at)
+		}
+		return
+	}
+
+	if f != nil {
+		e.add(f, at)
+	}
+}
+
+// joinType is the type of the errors built by errors.Join
+var joinType = reflect.TypeOf(errors.Join(errors.New("")))
+
+// members returns the errors held by e when it is an aggregate, built by
+// errors.Join or by a package such as hashicorp/go-multierror exposing
+// WrappedErrors. Other errors wrapping several, such as fmt.Errorf with
+// more than one %w, are single failures adding context of their own.
+func members(e error) ([]error, bool) {
+	if e != nil && reflect.TypeOf(e) == joinType {
+		return e.(interface{ Unwrap() []error }).Unwrap(), true
+	}
+
+	if x, ok := e.(interface{ WrappedErrors() []error }); ok {
+		return x.WrappedErrors(), true
+	}
+
+	return nil, false
+}
+
+// add appends f to the failures, recording at as the time it occurred, it
+// only counts f once the limit is reached
+func (e *Multi) add(f error, at time.Time) {
+	if e.limit > 0 && len(e.Failures) >= e.limit {
+		e.truncated++
+		return
+	}
+
+	e.padOccurred()
+	e.Failures = append(e.Failures, f)
+	e.occurred = append(e.occurred, at)
+}
+
+// padOccurred lines the times up with failures set by hand
+func (e *Multi) padOccurred() {
+	for len(e.occurred) < len(e.Failures) {
+		e.occurred = append(e.occurred, time.Time{})
+	}
+}
+
+// OccurredAt returns when the i-th failure was added with Append. The times
+// follow the failures through SortByTime, sort.Sort and Flatten, failures
+// of a Multi built with Multiple, or set by hand, carry no time.
+func (e *Multi) OccurredAt(i int) (time.Time, bool) {
+	if e == nil || i < 0 || i >= len(e.occurred) || i >= len(e.Failures) || e.occurred[i].IsZero() {
+		return time.Time{}, false
+	}
+
+	return e.occurred[i], true
+}
+
+// SortByTime orders the failures of e chronologically, see OccurredAt.
+// Failures without a time keep their order after the others.
+func (e *Multi) SortByTime() {
+	if e == nil {
+		return
+	}
+
+	e.padOccurred()
+	sort.Stable(byTime{e})
+}
+
+// byTime sorts the failures of a Multi by the time they occurred
+type byTime struct {
+	*Multi
+}
+
+func (b byTime) Less(i, j int) bool {
+	ti, iok := b.OccurredAt(i)
+	tj, jok := b.OccurredAt(j)
+	if iok != jok {
+		return iok
+	}
+	return iok && ti.Before(tj)
+}
+
+// Timeline describes the failures chronologically, each one with the time
+// elapsed since the first, so a report of a long running job shows when
+// things went wrong:
+//
+//	3 errors occurred starting at 2022-03-01T10:00:00Z:
+//		* +0s: fetch page 1: timeout
+//		* +1m4.5s: fetch page 7: timeout
+//		* +2m0s: save results: system failure
+//
+// Failures without a time are listed last, without an offset.
+func (e *Multi) Timeline() string {
+	sorted := &Multi{}
+	if e != nil {
+		sorted.Failures = append([]error(nil), e.Failures...)
+		sorted.occurred = append([]time.Time(nil), e.occurred...)
+	}
+	sorted.SortByTime()
+
+	var start time.Time
+	var started bool
+	points := make([]string, len(sorted.Failures))
+	for i, err := range sorted.Failures {
+		at, ok := sorted.OccurredAt(i)
+		switch {
+		case !ok:
+			points[i] = fmt.Sprintf("* %s", err)
+			continue
+		case !started:
+			start, started = at, true
+		}
+		points[i] = fmt.Sprintf("* +%s: %s", at.Sub(start).Round(time.Millisecond), err)
+	}
+
+	noun := "errors"
+	if len(points) == 1 {
+		noun = "error"
+	}
+	header := fmt.Sprintf("%d %s occurred", len(points), noun)
+	if started {
+		header += " starting at " + start.Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("%s:\n\t%s\n\n", header, strings.Join(points, "\n\t"))
+}
+
+// Filter returns a Multi holding the failures of e that pred accepts, in
+// order and with the time they occurred. e is left untouched and nested
+// Multi are handled as single failures, Flatten first to reach their
+// members.
+//
+//	retryable := batch.Filter(failure.IsRetryable)
+func (e *Multi) Filter(pred func(error) bool) *Multi {
+	matched, _ := e.Partition(pred)
+	return matched
+}
+
+// Partition splits the failures of e into those pred accepts and the rest,
+// see Filter, so a batch processor can requeue the retryable failures and
+// dead letter the permanent ones:
+//
+//	retry, permanent := batch.Partition(failure.IsRetryable)
+func (e *Multi) Partition(pred func(error) bool) (*Multi, *Multi) {
+	if e == nil {
+		return nil, nil
+	}
+
+	matched := &Multi{Formatter: e.Formatter}
+	rest := &Multi{Formatter: e.Formatter}
+	for i, err := range e.Failures {
+		at, _ := e.OccurredAt(i)
+		if pred(err) {
+			matched.add(err, at)
+		} else {
+			rest.add(err, at)
+		}
+	}
+
+	return matched, rest
+}
+
+// Map returns a Multi holding the result of fn for every failure of e, in
+// order and with the time they occurred, failures mapped to nil are left
+// out. e is left untouched.
+//
+//	batch = batch.Map(func(err error) error {
+//		return failure.Wrap(err, "sync tenant %s", id)
+//	})
+func (e *Multi) Map(fn func(error) error) *Multi {
+	if e == nil {
+		return nil
+	}
+
+	out := &Multi{Formatter: e.Formatter}
+	for i, err := range e.Failures {
+		if mapped := fn(err); mapped != nil {
+			at, _ := e.OccurredAt(i)
+			out.add(mapped, at)
+		}
+	}
+
+	return out
+}
+
 // Flatten flattens the given error, merging any *Errors together into
-// a single *Error.
+// a single *Error. Errors built by errors.Join, or by another multi-error
+// package, are merged the same way.
 func Flatten(err error) error {
 	// If it isn't an *Error, just return the error as-is
 	if _, ok := err.(*Multi); !ok {
-		return err
+		if _, ok := members(err); !ok {
+			return err
+		}
 	}
 
 	// Otherwise, make the result and flatten away!
@@ -216,11 +745,27 @@ func Flatten(err error) error {
 func flatten(err error, flatErr *Multi) {
 	switch err := err.(type) {
 	case *Multi:
-		for _, e := range err.Failures {
-			flatten(e, flatErr)
+		for i, e := range err.Failures {
+			if _, ok := e.(*Multi); ok {
+				flatten(e, flatErr)
+				continue
+			}
+			if _, ok := members(e); ok {
+				flatten(e, flatErr)
+				continue
+			}
+			at, _ := err.OccurredAt(i)
+			flatErr.add(e, at)
 		}
+		flatErr.truncated += err.truncated
 	default:
-		flatErr.Failures = append(flatErr.Failures, err)
+		if fs, ok := members(err); ok {
+			for _, f := range fs {
+				flatten(f, flatErr)
+			}
+			return
+		}
+		flatErr.add(err, time.Time{})
 	}
 }
 
@@ -229,9 +774,25 @@ func (e Multi) Len() int {
 	return len(e.Failures)
 }
 
-// Swap implements sort.Interface function for swapping elements
+// Swap implements sort.Interface function for swapping elements, the times
+// of the failures move with them
 func (e Multi) Swap(i, j int) {
 	e.Failures[i], e.Failures[j] = e.Failures[j], e.Failures[i]
+
+	// failures set by hand past the recorded times have none to trade
+	var ti, tj time.Time
+	if i < len(e.occurred) {
+		ti = e.occurred[i]
+	}
+	if j < len(e.occurred) {
+		tj = e.occurred[j]
+	}
+	if i < len(e.occurred) {
+		e.occurred[i] = tj
+	}
+	if j < len(e.occurred) {
+		e.occurred[j] = ti
+	}
 }
 
 // Less implements sort.Interface function for determining order
@@ -239,37 +800,222 @@ func (e Multi) Less(i, j int) bool {
 	return e.Failures[i].Error() < e.Failures[j].Error()
 }
 
+// SafeMulti accumulates failures from many goroutines, such as a long
+// lived collector fed by workers. The zero value is ready to use and it is
+// safe for concurrent use, unlike Multi.
+type SafeMulti struct {
+	mutex sync.Mutex
+	err   *Multi
+}
+
+// Append adds errs the same way the Append function does
+func (s *SafeMulti) Append(errs ...error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = Append(s.err, errs...)
+}
+
+// Len returns the number of failures appended so far
+func (s *SafeMulti) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.err.WrappedErrors())
+}
+
+// Multi returns a snapshot of the failures appended so far, later appends
+// don't change it.
+func (s *SafeMulti) Multi() *Multi {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err == nil {
+		return &Multi{}
+	}
+
+	m := *s.err
+	m.Failures = append([]error(nil), s.err.Failures...)
+	m.occurred = append([]time.Time(nil), s.err.occurred...)
+	return &m
+}
+
+// ErrorOrNil returns a snapshot of the failures as an error, or nil when
+// there are none, see Multi.ErrorOrNil.
+func (s *SafeMulti) ErrorOrNil() error {
+	return s.Multi().ErrorOrNil()
+}
+
 // Group is a collection of goroutines which return errors that need to be
 // coalesced.
 type Group struct {
 	mutex sync.Mutex
 	err   *Multi
 	wg    sync.WaitGroup
+	// limit bounds the failures retained, zero keeps them all
+	limit    int
+	keepLast bool
+	// next is where the following failure goes once the retained failures
+	// wrap around when keepLast is set
+	next    int
+	count   int
+	dropped int
+	// cancel is set by GroupWithContext
+	cancel context.CancelCauseFunc
+	// sem holds a slot per active goroutine, it is nil without a limit
+	sem chan struct{}
+}
+
+// GroupWithContext returns a Group and a context derived from ctx, like
+// errgroup.WithContext. The context is canceled by the first failure, with
+// that failure as its cause, or when Wait returns, whichever happens first.
+// Unlike errgroup, Wait still returns every failure, not just the first.
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
 }
 
-// Go calls the given function in a new goroutine.
+// RetainFirst bounds the group to the first n failures, the rest are only
+// counted. A task returning a Multi or a joined error adds each of its
+// members. It must be called before Go.
+func (g *Group) RetainFirst(n int) {
+	g.limit, g.keepLast = n, false
+}
+
+// RetainLast bounds the group to the last n failures, older ones are
+// discarded as newer ones arrive and only counted. It must be called before
+// Go.
+func (g *Group) RetainLast(n int) {
+	g.limit, g.keepLast = n, true
+}
+
+// Go calls the given function in a new goroutine. It blocks until a slot
+// is free when the number of active goroutines is limited, see SetLimit.
 //
 // If the function returns an error it is added to the group multierror which
 // is returned by Wait.
 func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.start(f)
+}
+
+// TryGo calls the given function in a new goroutine only when the number of
+// active goroutines is below the limit set with SetLimit, it reports
+// whether the function was started.
+func (g *Group) TryGo(f func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+
+	g.start(f)
+	return true
+}
+
+// SetLimit bounds the number of goroutines started with Go and TryGo that
+// are active at once to n, a negative n removes the limit. It must not be
+// called while goroutines of the group are active.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+
+	g.sem = make(chan struct{}, n)
+}
+
+func (g *Group) start(f func() error) {
 	g.wg.Add(1)
 
 	go func() {
 		defer g.wg.Done()
+		defer g.release()
 
 		if err := f(); err != nil {
 			g.mutex.Lock()
-			g.err = Append(g.err, err)
+			g.record(err)
 			g.mutex.Unlock()
+
+			if g.cancel != nil {
+				g.cancel(err)
+			}
 		}
 	}()
 }
 
+// release frees the slot held by a finished goroutine
+func (g *Group) release() {
+	if g.sem != nil {
+		<-g.sem
+	}
+}
+
+// Count returns the number of failures so far, retained or not
+func (g *Group) Count() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.count
+}
+
+// Dropped returns the number of failures that were not retained
+func (g *Group) Dropped() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.dropped
+}
+
 // Wait blocks until all function calls from the Go method have returned, then
 // returns the Multi
 func (g *Group) Wait() *Multi {
 	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
+
+	if g.keepLast && g.next > 0 {
+		// put the retained failures back in the order they happened
+		rotated := make([]error, 0, len(g.err.Failures))
+		rotated = append(rotated, g.err.Failures[g.next:]...)
+		g.err.Failures = append(rotated, g.err.Failures[:g.next]...)
+		times := make([]time.Time, 0, len(g.err.occurred))
+		times = append(times, g.err.occurred[g.next:]...)
+		g.err.occurred = append(times, g.err.occurred[:g.next]...)
+		g.next = 0
+	}
+
 	return g.err
 }
+
+// record keeps the failures of err within the limit, a Multi or joined
+// error counting as each of its members
+func (g *Group) record(err error) {
+	members := Append(nil, err)
+	g.count += members.truncated
+	g.dropped += members.truncated
+
+	for i, m := range members.Failures {
+		at, _ := members.OccurredAt(i)
+		g.count++
+
+		if g.limit <= 0 || g.err == nil || len(g.err.Failures) < g.limit {
+			if g.err == nil {
+				g.err = new(Multi)
+			}
+			g.err.add(m, at)
+			continue
+		}
+
+		g.dropped++
+		if g.keepLast {
+			g.err.Failures[g.next] = m
+			g.err.occurred[g.next] = at
+			g.next = (g.next + 1) % g.limit
+		}
+	}
+}
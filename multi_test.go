@@ -1,12 +1,15 @@
 package failure_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -310,8 +313,477 @@ func TestMultiResult(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, list, result)
 
+	result, ok = failure.MultiResult(failure.Wrap(failure.Wrap(err, "inner"), "outer"))
+	require.True(t, ok)
+	require.Equal(t, list, result)
+
 	e := errors.New("some other thing")
 	result, ok = failure.MultiResult(e)
 	require.False(t, ok)
 	require.Empty(t, result)
 }
+
+func TestAsMulti(t *testing.T) {
+	m := failure.Append(errors.New("a"), errors.New("b"))
+
+	got, ok := failure.AsMulti(fmt.Errorf("sync: %w", failure.Wrap(m, "load")))
+	require.True(t, ok)
+	assert.Same(t, m, got)
+
+	got, ok = failure.AsMulti(nil)
+	assert.False(t, ok)
+	assert.Nil(t, got)
+}
+
+func Test_MultiAppend(t *testing.T) {
+	original := &failure.Multi{Failures: []error{errors.New("foo")}}
+
+	result := failure.Append(original, errors.New("bar"))
+	assert.Len(t, result.Failures, 2)
+	assert.Same(t, original, result)
+
+	result = failure.Append(original, &failure.Multi{Failures: []error{errors.New("bar"), errors.New("baz")}})
+	assert.Len(t, result.Failures, 4)
+
+	var nilMulti *failure.Multi
+	result = failure.Append(nilMulti, errors.New("foo"), nil)
+	assert.Len(t, result.Failures, 1)
+
+	result = failure.Append(errors.New("foo"), errors.New("bar"))
+	assert.Len(t, result.Failures, 2)
+
+	result = failure.Append(nil, nil, nilMulti)
+	require.NotNil(t, result)
+	assert.NoError(t, result.ErrorOrNil())
+}
+
+func Test_MultiPrefix(t *testing.T) {
+	assert.Nil(t, failure.Prefix(nil, "foo"))
+
+	err := failure.Prefix(failure.NotFound("user"), "load:")
+	assert.Equal(t, "load: user: "+failure.NotFoundMsg, err.Error())
+	assert.True(t, failure.IsNotFound(err))
+
+	multi := failure.Append(nil, errors.New("foo"), failure.Timeout("bar"))
+	err = failure.Prefix(multi, "sync:")
+	assert.Equal(t, "sync: foo", multi.Failures[0].Error())
+	assert.True(t, failure.IsTimeout(multi.Failures[1]))
+	assert.Same(t, multi, err)
+}
+
+func Test_MultiSummary(t *testing.T) {
+	var nilMulti *failure.Multi
+	assert.Empty(t, nilMulti.Summary())
+
+	multi := failure.Append(nil,
+		failure.Timeout("a"),
+		failure.Validation("b"),
+		failure.Timeout("c"),
+		failure.Append(nil, failure.Timeout("d"), errors.New("e")),
+	)
+
+	assert.Equal(t, map[string]int{
+		"timeout":             3,
+		"validation":          1,
+		failure.Uncategorized: 1,
+	}, multi.Summary())
+
+	multi.Formatter = failure.SummaryFormatFn
+	assert.True(t, strings.HasPrefix(multi.Error(),
+		"5 failures: 3 timeout, 1 uncategorized, 1 validation\n\t* "))
+
+	single := failure.Multiple([]error{failure.System("x")}, failure.SummaryFormatFn)
+	assert.True(t, strings.HasPrefix(single.Error(), "1 failure: 1 system\n\t* x"))
+}
+
+func Test_Multi_GroupRetain(t *testing.T) {
+	run := func(g *failure.Group) *failure.Multi {
+		for i := 0; i < 10; i++ {
+			i := i
+			g.Go(func() error { return fmt.Errorf("%d", i) })
+			// let each function finish so the order is known
+			for g.Count() <= i {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		return g.Wait()
+	}
+
+	messages := func(m *failure.Multi) []string {
+		var out []string
+		for _, err := range m.Failures {
+			out = append(out, err.Error())
+		}
+		return out
+	}
+
+	var first failure.Group
+	first.RetainFirst(3)
+	assert.Equal(t, []string{"0", "1", "2"}, messages(run(&first)))
+	assert.Equal(t, 7, first.Dropped())
+	assert.Equal(t, 10, first.Count())
+
+	var last failure.Group
+	last.RetainLast(3)
+	kept := run(&last)
+	assert.Equal(t, []string{"7", "8", "9"}, messages(kept))
+	assert.Equal(t, 7, last.Dropped())
+	for i := range kept.Failures {
+		_, ok := kept.OccurredAt(i)
+		assert.True(t, ok)
+	}
+
+	var all failure.Group
+	assert.Len(t, run(&all).Failures, 10)
+	assert.Zero(t, all.Dropped())
+
+	joined := func(g *failure.Group) *failure.Multi {
+		g.Go(func() error {
+			return errors.Join(errors.New("a"), errors.New("b"), errors.New("c"), errors.New("d"))
+		})
+		return g.Wait()
+	}
+
+	var firstJoined failure.Group
+	firstJoined.RetainFirst(3)
+	assert.Equal(t, []string{"a", "b", "c"}, messages(joined(&firstJoined)))
+	assert.Equal(t, 1, firstJoined.Dropped())
+	assert.Equal(t, 4, firstJoined.Count())
+
+	var lastJoined failure.Group
+	lastJoined.RetainLast(3)
+	assert.Equal(t, []string{"b", "c", "d"}, messages(joined(&lastJoined)))
+	assert.Equal(t, 1, lastJoined.Dropped())
+}
+
+func Test_MultiErrorOrNilExcluding(t *testing.T) {
+	var nilMulti *failure.Multi
+	assert.NoError(t, nilMulti.ErrorOrNilExcluding(failure.IsWarn))
+
+	multi := failure.Append(nil,
+		failure.Warn("a"),
+		failure.Append(nil, failure.Ignore("b")),
+	)
+	assert.NoError(t, multi.ErrorOrNilExcluding(failure.IsWarn, failure.IsIgnore))
+	assert.Error(t, multi.ErrorOrNilExcluding(failure.IsWarn))
+	assert.Len(t, multi.Failures, 2)
+
+	multi = failure.Append(multi, failure.System("c"))
+	err := multi.ErrorOrNilExcluding(failure.IsWarn, failure.IsIgnore)
+	assert.Same(t, multi, err)
+}
+
+func Test_MultiTimeline(t *testing.T) {
+	first := failure.Timeout("first")
+	multi := failure.Append(nil, first)
+	time.Sleep(5 * time.Millisecond)
+	second := failure.System("second")
+	multi = failure.Append(multi, second)
+
+	assert.True(t, multi.Failures[0] == first)
+	at1, ok := multi.OccurredAt(0)
+	require.True(t, ok)
+	at2, _ := multi.OccurredAt(1)
+	assert.True(t, at2.After(at1))
+
+	_, ok = failure.Multiple([]error{first}).OccurredAt(0)
+	assert.False(t, ok)
+
+	untimed := errors.New("untimed")
+	multi.Failures = append(multi.Failures, untimed)
+	multi.Swap(0, 1)
+	multi.SortByTime()
+	assert.Equal(t, []error{first, second, untimed}, multi.Failures)
+
+	flat := failure.Flatten(failure.Append(nil, multi)).(*failure.Multi)
+	at, ok := flat.OccurredAt(0)
+	require.True(t, ok)
+	assert.Equal(t, at1, at)
+
+	lines := strings.Split(multi.Timeline(), "\n\t")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "3 errors occurred starting at "))
+	assert.Equal(t, "* +0s: first: "+failure.TimeoutMsg, lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "* +"))
+	assert.NotEqual(t, "* +0s", lines[2][:5])
+	assert.Equal(t, "* untimed\n\n", lines[3])
+}
+
+func Test_SafeMulti(t *testing.T) {
+	var s failure.SafeMulti
+	assert.NoError(t, s.ErrorOrNil())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Append(fmt.Errorf("%d", i), nil)
+			_ = s.Len()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, s.Len())
+	snapshot := s.Multi()
+	s.Append(errors.New("late"))
+	assert.Len(t, snapshot.Failures, 10)
+	assert.Equal(t, 11, s.Len())
+
+	err := s.ErrorOrNil()
+	require.Error(t, err)
+	assert.True(t, failure.IsMultiple(err))
+
+	limited := new(failure.Multi)
+	limited.SetLimit(1)
+	s.Append(failure.Append(limited, errors.New("a"), errors.New("b"), errors.New("c")))
+	snapshot = s.Multi()
+	assert.Equal(t, 2, snapshot.Truncated())
+	assert.Contains(t, snapshot.Error(), "… and 2 more")
+}
+
+func Test_GroupWithContext(t *testing.T) {
+	errBoom := errors.New("boom")
+	g, ctx := failure.GroupWithContext(context.Background())
+
+	g.Go(func() error { return errBoom })
+	g.Go(func() error {
+		<-ctx.Done()
+		return failure.WrapContext(ctx, ctx.Err(), "worker")
+	})
+
+	err := g.Wait()
+	require.Error(t, err.ErrorOrNil())
+	assert.Len(t, err.Failures, 2)
+	assert.True(t, failure.IsCanceled(err))
+	assert.ErrorIs(t, context.Cause(ctx), errBoom)
+
+	g, ctx = failure.GroupWithContext(context.Background())
+	g.Go(func() error { return nil })
+	assert.Nil(t, g.Wait())
+	assert.Error(t, ctx.Err())
+}
+
+func Test_GroupLimit(t *testing.T) {
+	var g failure.Group
+	g.SetLimit(2)
+
+	var mutex sync.Mutex
+	active, peak := 0, 0
+	for i := 0; i < 10; i++ {
+		i := i
+		g.Go(func() error {
+			mutex.Lock()
+			active++
+			if active > peak {
+				peak = active
+			}
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mutex.Lock()
+			active--
+			mutex.Unlock()
+			return fmt.Errorf("%d", i)
+		})
+	}
+	assert.Len(t, g.Wait().Failures, 10)
+	assert.LessOrEqual(t, peak, 2)
+
+	release := make(chan struct{})
+	g.SetLimit(1)
+	assert.True(t, g.TryGo(func() error { <-release; return nil }))
+	assert.False(t, g.TryGo(func() error { return nil }))
+	close(release)
+	g.Wait()
+	assert.True(t, g.TryGo(func() error { return nil }))
+	g.Wait()
+}
+
+func Test_MultiFilterPartitionMap(t *testing.T) {
+	timeout := failure.Timeout("fetch page 2")
+	invalid := failure.Validation("page 3")
+	system := failure.System("disk full")
+	multi := failure.Append(nil, timeout, invalid, system)
+
+	retry, permanent := multi.Partition(failure.IsRetryable)
+	assert.Equal(t, []error{timeout}, retry.Failures)
+	assert.Equal(t, []error{invalid, system}, permanent.Failures)
+	_, ok := permanent.OccurredAt(1)
+	assert.True(t, ok)
+
+	filtered := multi.Filter(failure.IsValidation)
+	assert.Equal(t, []error{invalid}, filtered.Failures)
+	assert.NoError(t, multi.Filter(failure.IsNotFound).ErrorOrNil())
+	assert.Len(t, multi.Failures, 3)
+
+	mapped := multi.Map(func(err error) error {
+		if failure.IsValidation(err) {
+			return nil
+		}
+		return failure.Wrap(err, "sync")
+	})
+	require.Len(t, mapped.Failures, 2)
+	assert.True(t, failure.IsTimeout(mapped.Failures[0]))
+	assert.Equal(t, "sync: fetch page 2: "+failure.TimeoutMsg, mapped.Failures[0].Error())
+
+	var nilMulti *failure.Multi
+	assert.Nil(t, nilMulti.Filter(failure.IsTimeout))
+	assert.Nil(t, nilMulti.Map(func(err error) error { return err }))
+}
+
+func Test_MultiByKind(t *testing.T) {
+	raw := errors.New("raw")
+	multi := failure.Append(nil,
+		failure.Timeout("a"),
+		failure.Validation("b"),
+		failure.Append(nil, failure.Timeout("c"), raw),
+	)
+
+	groups := multi.ByKind()
+	assert.Len(t, groups, 3)
+	require.Len(t, groups[failure.KindTimeout], 2)
+	assert.Equal(t, "c: "+failure.TimeoutMsg, groups[failure.KindTimeout][1].Error())
+	assert.Len(t, groups[failure.KindValidation], 1)
+	assert.Equal(t, []error{raw}, groups[failure.Uncategorized])
+
+	var nilMulti *failure.Multi
+	assert.Empty(t, nilMulti.ByKind())
+}
+
+type closer struct {
+	err    error
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func Test_AppendInto(t *testing.T) {
+	var err error
+	assert.False(t, failure.AppendInto(&err, nil))
+	assert.NoError(t, err)
+
+	first := failure.NotFound("user")
+	assert.True(t, failure.AppendInto(&err, first))
+	assert.Equal(t, first, err)
+
+	second := failure.System("close")
+	assert.True(t, failure.AppendInto(&err, second))
+	multi, ok := err.(*failure.Multi)
+	require.True(t, ok)
+	assert.Equal(t, []error{first, second}, multi.Failures)
+
+	assert.Panics(t, func() { failure.AppendInto(nil, first) })
+}
+
+func Test_AppendClose(t *testing.T) {
+	c := &closer{err: failure.System("flush failed")}
+	read := func() (err error) {
+		defer failure.AppendClose(&err, c)
+		return failure.Timeout("read")
+	}
+
+	err := read()
+	assert.True(t, c.closed)
+	assert.True(t, failure.IsTimeout(err))
+	assert.True(t, errors.Is(err, failure.KindSystem))
+
+	ok := func() (err error) {
+		defer failure.AppendClose(&err, &closer{})
+		return nil
+	}
+	assert.NoError(t, ok())
+}
+
+func Test_MultiLimit(t *testing.T) {
+	result := new(failure.Multi)
+	result.SetLimit(2)
+	for i := 0; i < 44; i++ {
+		result = failure.Append(result, fmt.Errorf("item %d", i))
+	}
+
+	assert.Len(t, result.Failures, 2)
+	assert.Equal(t, 42, result.Truncated())
+	assert.Equal(t, "2 errors occurred:\n\t* item 0\n\t* item 1\n\t… and 42 more\n\n", result.Error())
+
+	other := new(failure.Multi)
+	other.SetLimit(1)
+	other = failure.Append(other, errors.New("a"), errors.New("b"))
+	merged := failure.Append(nil, other, errors.New("c"))
+	assert.Len(t, merged.Failures, 2)
+	assert.Equal(t, 1, merged.Truncated())
+
+	flat, ok := failure.Flatten(failure.Append(nil, result)).(*failure.Multi)
+	require.True(t, ok)
+	assert.Equal(t, 42, flat.Truncated())
+
+	m := failure.Multiple([]error{errors.New("a"), errors.New("b"), errors.New("c")})
+	m.SetLimit(1)
+	assert.Len(t, m.Failures, 1)
+	assert.Equal(t, 2, m.Truncated())
+
+	var nilMulti *failure.Multi
+	assert.Zero(t, nilMulti.Truncated())
+}
+
+// wrappedErrors stands for hashicorp/go-multierror
+type wrappedErrors []error
+
+func (w wrappedErrors) Error() string          { return "wrapped" }
+func (w wrappedErrors) WrappedErrors() []error { return w }
+
+func Test_MultiAppendJoined(t *testing.T) {
+	a, b, c, d := errors.New("a"), errors.New("b"), errors.New("c"), errors.New("d")
+
+	result := failure.Append(errors.Join(a, errors.Join(b, nil)), wrappedErrors{c}, failure.NotFound("d"))
+	require.Len(t, result.Failures, 4)
+	assert.Equal(t, []error{a, b, c}, result.Failures[:3])
+	assert.True(t, failure.IsNotFound(result.Failures[3]))
+
+	wrapped := failure.ToSystem(d, "save")
+	result = failure.Append(nil, wrapped)
+	assert.Equal(t, []error{wrapped}, result.Failures)
+
+	flat, ok := failure.Flatten(errors.Join(a, failure.Append(b, errors.Join(c, d)))).(*failure.Multi)
+	require.True(t, ok)
+	assert.Equal(t, []error{a, b, c, d}, flat.Failures)
+
+	assert.Equal(t, a, failure.Flatten(a))
+
+	cfg := fmt.Errorf("loading cfg: %w, %w", a, b)
+	result = failure.Append(nil, cfg)
+	assert.Equal(t, []error{cfg}, result.Failures)
+	assert.Contains(t, result.Error(), "loading cfg")
+	assert.Equal(t, cfg, failure.Flatten(cfg))
+}
+
+func Test_MultiIsAsDeep(t *testing.T) {
+	errBar := errors.New("bar")
+	match := &nestedError{}
+
+	inner := failure.Append(errors.New("foo"), failure.Wrap(fmt.Errorf("errorf: %w", errBar), "second"))
+	err := failure.Wrap(failure.Wrap(&failure.Multi{Failures: []error{
+		nil,
+		errors.New("baz"),
+		inner,
+		failure.To(failure.KindSystem, match, "third"),
+	}}, "outer"), "outermost")
+
+	assert.True(t, errors.Is(err, errBar))
+	assert.True(t, errors.Is(err, failure.KindSystem))
+	assert.False(t, errors.Is(err, errors.New("bar")))
+
+	var target *nestedError
+	require.True(t, errors.As(err, &target))
+	assert.Same(t, match, target)
+
+	var m *failure.Multi
+	require.True(t, errors.As(err, &m))
+	assert.Len(t, m.Failures, 4)
+	assert.True(t, failure.IsMultiple(err))
+	assert.False(t, failure.IsMultiple(errBar))
+}
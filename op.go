@@ -0,0 +1,56 @@
+package failure
+
+// Operation names the operation that failed, such as "orders.Create", when
+// passed to E.
+type Operation string
+
+// operation prefixes an error with the operation that produced it
+type operation struct {
+	op  Operation
+	err error
+}
+
+func (o *operation) Error() string {
+	return string(o.op) + ": " + o.err.Error()
+}
+
+func (o *operation) Unwrap() error {
+	return o.err
+}
+
+// Op prefixes err with the name of the operation that failed, kept apart
+// from the message so each layer adds a short machine readable step:
+//
+//	func (s *Orders) Create(ctx context.Context, o Order) error {
+//		if err := s.db.Insert(ctx, o); err != nil {
+//			return failure.Op("orders.Create", err)
+//		}
+//
+// reads "orders.Create: db.Insert: not found failure" when db.Insert did
+// the same, and Ops returns both names. nil is returned when err is nil.
+func Op(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &operation{op: Operation(op), err: err}
+}
+
+// Ops returns the operations in the chain of e added with Op or E,
+// outermost first. The search stops at a Multi, its members failed in
+// operations of their own.
+func Ops(e error) []string {
+	var ops []string
+	for e != nil {
+		switch x := e.(type) {
+		case *operation:
+			ops = append(ops, string(x.op))
+		case *Multi:
+			return ops
+		}
+
+		e = next(e)
+	}
+
+	return ops
+}
@@ -0,0 +1,25 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOp(t *testing.T) {
+	insert := failure.Op("db.Insert", failure.KindNotFound)
+	err := failure.Op("orders.Create", failure.WithDetail(insert, "order", 7))
+
+	assert.True(t, failure.IsNotFound(err))
+	assert.Equal(t, []string{"orders.Create", "db.Insert"}, failure.Ops(err))
+	assert.Equal(t, "orders.Create: db.Insert: "+failure.NotFoundMsg, err.Error())
+
+	built := failure.E(failure.Operation("orders.Get"), failure.KindNotFound, "order 7")
+	assert.Equal(t, []string{"orders.Get"}, failure.Ops(failure.Wrap(built, "handler")))
+
+	assert.NoError(t, failure.Op("orders.Create", nil))
+	assert.Empty(t, failure.Ops(errors.New("plain")))
+	assert.Empty(t, failure.Ops(failure.Append(nil, insert)))
+}
@@ -0,0 +1,45 @@
+package failure
+
+type panicValueKey struct{}
+
+// FromPanic converts a value recovered from a panic into a Panic failure
+// carrying the stack trace of the panic. When the value is an error it stays
+// reachable with errors.Is and errors.As, the value itself is read back with
+// PanicValue.
+func FromPanic(v interface{}) error {
+	return fromPanic(v, 4)
+}
+
+// RecoverPanic recovers a panic in progress and stores it into errp as a
+// Panic failure, see FromPanic. It must be deferred directly:
+//
+//	func (s *Service) Handle(ctx context.Context) (err error) {
+//		defer failure.RecoverPanic(&err)
+//		...
+//	}
+//
+// An error already held by errp is kept alongside the panic, see AppendInto.
+func RecoverPanic(errp *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	AppendInto(errp, fromPanic(v, 4))
+}
+
+// PanicValue returns the value recovered by FromPanic or RecoverPanic
+func PanicValue(e error) (interface{}, bool) {
+	return lookup(e, panicValueKey{})
+}
+
+func fromPanic(v interface{}, skip int) error {
+	var err error
+	if e, ok := v.(error); ok {
+		err = &classified{err: e, kind: KindPanic}
+	} else {
+		err = Panic("%v", v)
+	}
+
+	return annotate(annotate(err, panicValueKey{}, v), stackKey{}, callers(skip))
+}
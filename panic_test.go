@@ -0,0 +1,66 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromPanic(t *testing.T) {
+	var err error
+	func() {
+		defer func() {
+			err = failure.FromPanic(recover())
+		}()
+		panic("nil map")
+	}()
+
+	assert.True(t, failure.IsPanic(err))
+	assert.Equal(t, "nil map: "+failure.PanicMsg, err.Error())
+
+	frames, ok := failure.StackTrace(err)
+	require.True(t, ok)
+	assert.Contains(t, failure.FormatStack(frames), "TestFromPanic")
+
+	cause := errors.New("boom")
+	err = failure.FromPanic(cause)
+	assert.True(t, failure.IsPanic(err))
+	assert.True(t, errors.Is(err, cause))
+	assert.Equal(t, "boom: "+failure.PanicMsg, err.Error())
+}
+
+func TestRecoverPanic(t *testing.T) {
+	run := func(v interface{}) (err error) {
+		defer failure.RecoverPanic(&err)
+		if v != nil {
+			panic(v)
+		}
+		return nil
+	}
+
+	assert.NoError(t, run(nil))
+
+	err := run("nil map")
+	assert.True(t, failure.IsPanic(err))
+	assert.Equal(t, "nil map: "+failure.PanicMsg, err.Error())
+
+	v, ok := failure.PanicValue(err)
+	require.True(t, ok)
+	assert.Equal(t, "nil map", v)
+
+	frames, ok := failure.StackTrace(err)
+	require.True(t, ok)
+	assert.Contains(t, failure.FormatStack(frames), "TestRecoverPanic")
+
+	cause := errors.New("boom")
+	err = run(cause)
+	assert.True(t, errors.Is(err, cause))
+	v, _ = failure.PanicValue(err)
+	assert.Equal(t, cause, v)
+
+	_, ok = failure.PanicValue(failure.System("boom"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,77 @@
+package failure
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParamDetail and ValueDetail are the detail keys holding the name and the
+// raw value of a parameter that failed to parse.
+const (
+	ParamDetail = "param"
+	ValueDetail = "value"
+)
+
+// ParseInt is strconv.ParseInt returning an InvalidParam failure naming
+// the parameter and carrying its raw value, see ParamDetail and ValueDetail.
+func ParseInt(name, s string, base, bits int) (int64, error) {
+	v, err := strconv.ParseInt(s, base, bits)
+	return v, invalidParam(name, s, err)
+}
+
+// ParseUint is strconv.ParseUint returning an InvalidParam failure, see
+// ParseInt.
+func ParseUint(name, s string, base, bits int) (uint64, error) {
+	v, err := strconv.ParseUint(s, base, bits)
+	return v, invalidParam(name, s, err)
+}
+
+// ParseBool is strconv.ParseBool returning an InvalidParam failure, see
+// ParseInt.
+func ParseBool(name, s string) (bool, error) {
+	v, err := strconv.ParseBool(s)
+	return v, invalidParam(name, s, err)
+}
+
+// ParseDuration is time.ParseDuration returning an InvalidParam failure,
+// see ParseInt.
+func ParseDuration(name, s string) (time.Duration, error) {
+	v, err := time.ParseDuration(s)
+	return v, invalidParam(name, s, err)
+}
+
+// ParseUUID parses the canonical 36 character form of a UUID, braces and
+// a "urn:uuid:" prefix are accepted. The result converts directly to the
+// UUID types of the common libraries, which are all [16]byte. It returns an
+// InvalidParam failure, see ParseInt.
+func ParseUUID(name, s string) ([16]byte, error) {
+	var id [16]byte
+
+	raw := strings.TrimPrefix(strings.ToLower(s), "urn:uuid:")
+	if len(raw) == 38 && raw[0] == '{' && raw[37] == '}' {
+		raw = raw[1:37]
+	}
+	if len(raw) != 36 || raw[8] != '-' || raw[13] != '-' || raw[18] != '-' || raw[23] != '-' {
+		return id, invalidParam(name, s, errors.New("invalid UUID format"))
+	}
+
+	digits := raw[0:8] + raw[9:13] + raw[14:18] + raw[19:23] + raw[24:36]
+	if _, err := hex.Decode(id[:], []byte(digits)); err != nil {
+		return [16]byte{}, invalidParam(name, s, err)
+	}
+
+	return id, nil
+}
+
+func invalidParam(name, value string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := classify(err, KindInvalidParam, "invalid value (%q) for param (%s)", value, name)
+	e = WithDetail(e, ParamDetail, name)
+	return WithDetail(e, ValueDetail, value)
+}
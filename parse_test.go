@@ -0,0 +1,65 @@
+package failure_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInt(t *testing.T) {
+	v, err := failure.ParseInt("limit", "42", 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), v)
+
+	_, err = failure.ParseInt("limit", "forty", 10, 64)
+	assert.True(t, failure.IsInvalidParam(err))
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	assert.Contains(t, err.Error(), `invalid value ("forty") for param (limit)`)
+
+	name, _ := failure.Detail(err, failure.ParamDetail)
+	assert.Equal(t, "limit", name)
+	value, _ := failure.Detail(err, failure.ValueDetail)
+	assert.Equal(t, "forty", value)
+
+	_, err = failure.ParseUint("page", "-1", 10, 32)
+	assert.True(t, failure.IsInvalidParam(err))
+}
+
+func TestParseBoolDuration(t *testing.T) {
+	b, err := failure.ParseBool("verbose", "true")
+	require.NoError(t, err)
+	assert.True(t, b)
+	_, err = failure.ParseBool("verbose", "maybe")
+	assert.True(t, failure.IsInvalidParam(err))
+
+	d, err := failure.ParseDuration("timeout", "1m30s")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+	_, err = failure.ParseDuration("timeout", "soon")
+	assert.True(t, failure.IsInvalidParam(err))
+}
+
+func TestParseUUID(t *testing.T) {
+	want := [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+	for _, s := range []string{
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+		"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
+		"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	} {
+		id, err := failure.ParseUUID("id", s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, id)
+	}
+
+	for _, s := range []string{"", "6ba7b810", "6ba7b810-9dad-11d1-80b4-00c04fd430cz", "6ba7b8109dad-11d1-80b4-00c04fd430c8-"} {
+		_, err := failure.ParseUUID("id", s)
+		assert.True(t, failure.IsInvalidParam(err), s)
+	}
+}
@@ -0,0 +1,122 @@
+package failure
+
+import "sync"
+
+// Handle tracks a task submitted to a Pool
+type Handle struct {
+	done chan struct{}
+	err  error
+}
+
+// Done is closed once the task has finished
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the task has finished and returns its failure
+func (h *Handle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+type poolTask struct {
+	fn     func() error
+	handle *Handle
+}
+
+// Pool is a fixed number of long-lived workers running submitted tasks. It
+// accumulates task failures the same way Group does, panics in a task are
+// recovered and collected as Panic failures.
+type Pool struct {
+	tasks chan poolTask
+	wg    sync.WaitGroup
+	// quit is closed by Close, it stops the workers and fails the Submit
+	// calls still waiting for one
+	quit  chan struct{}
+	once  sync.Once
+	mutex sync.Mutex
+	err   *Multi
+}
+
+// NewPool starts a Pool with size workers
+func NewPool(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+
+	p := Pool{tasks: make(chan poolTask), quit: make(chan struct{})}
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+
+	return &p
+}
+
+// Submit hands task to the next free worker, blocking until one is
+// available. Submitting to a closed pool, or while it is being closed,
+// fails the task with InvalidState.
+func (p *Pool) Submit(task func() error) *Handle {
+	h := &Handle{done: make(chan struct{})}
+
+	select {
+	case <-p.quit:
+	default:
+		select {
+		case p.tasks <- poolTask{fn: task, handle: h}:
+			return h
+		case <-p.quit:
+		}
+	}
+
+	h.err = InvalidState("pool is closed")
+	close(h.done)
+	return h
+}
+
+// Close waits for the tasks handed to a worker to finish, stops the
+// workers and returns the accumulated failures, nil when every task
+// succeeded. Submit calls still waiting for a worker fail.
+func (p *Pool) Close() *Multi {
+	p.once.Do(func() {
+		close(p.quit)
+	})
+
+	p.wg.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.err
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case t := <-p.tasks:
+			err := run(t.fn)
+			if err != nil {
+				p.mutex.Lock()
+				p.err = Append(p.err, err)
+				p.mutex.Unlock()
+			}
+
+			t.handle.err = err
+			close(t.handle.done)
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// run calls fn, converting a panic into a Panic failure
+func run(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = FromPanic(v)
+		}
+	}()
+
+	return fn()
+}
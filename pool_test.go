@@ -0,0 +1,90 @@
+package failure_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	p := failure.NewPool(3)
+
+	var ran int64
+	var handles []*failure.Handle
+	for i := 0; i < 20; i++ {
+		i := i
+		handles = append(handles, p.Submit(func() error {
+			atomic.AddInt64(&ran, 1)
+			switch {
+			case i == 5:
+				return failure.NotFound("item %d", i)
+			case i == 7:
+				panic("nil map")
+			}
+			return nil
+		}))
+	}
+
+	assert.True(t, failure.IsNotFound(handles[5].Wait()))
+	assert.True(t, failure.IsPanic(handles[7].Wait()))
+	assert.NoError(t, handles[0].Wait())
+	<-handles[1].Done()
+
+	result := p.Close()
+	assert.Equal(t, int64(20), atomic.LoadInt64(&ran))
+	require.NotNil(t, result)
+	require.Len(t, result.Failures, 2)
+
+	err := p.Submit(func() error { return nil }).Wait()
+	assert.True(t, failure.IsInvalidState(err))
+
+	assert.Equal(t, result, p.Close())
+}
+
+func TestPool_NoFailures(t *testing.T) {
+	p := failure.NewPool(0)
+	p.Submit(func() error { return nil })
+	assert.Nil(t, p.Close())
+
+	var m *failure.Multi
+	assert.False(t, errors.As(p.Close().ErrorOrNil(), &m))
+}
+
+func TestPool_CloseWhileSubmitting(t *testing.T) {
+	p := failure.NewPool(1)
+
+	release := make(chan struct{})
+	var nested *failure.Handle
+	first := p.Submit(func() error {
+		<-release
+		nested = p.Submit(func() error { return nil })
+		return nil
+	})
+
+	waiting := make(chan *failure.Handle)
+	go func() {
+		waiting <- p.Submit(func() error { return nil })
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan *failure.Multi)
+	go func() {
+		closed <- p.Close()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close deadlocked with a waiting Submit")
+	}
+	assert.NoError(t, first.Wait())
+	assert.True(t, failure.IsInvalidState(nested.Wait()))
+	<-(<-waiting).Done()
+}
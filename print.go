@@ -0,0 +1,165 @@
+package failure
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PrintOption configures Fprint
+type PrintOption func(*printer)
+
+// PrintStack makes Fprint write the whole stack trace instead of only the
+// frame where the failure originated.
+func PrintStack() PrintOption {
+	return func(p *printer) {
+		p.stack = true
+	}
+}
+
+// PrintIndent sets the indentation of each nesting level, two spaces by
+// default.
+func PrintIndent(indent string) PrintOption {
+	return func(p *printer) {
+		p.indent = indent
+	}
+}
+
+type printer struct {
+	w      io.Writer
+	indent string
+	stack  bool
+	err    error
+}
+
+// Fprint writes a multi-line breakdown of e to w: its message, category,
+// severity, origin and details followed by every layer of its chain, one
+// per line. Members of a Multi are broken down the same way, indented under
+// it. It is meant for incident tickets and debug endpoints, not for logs.
+func Fprint(w io.Writer, e error, opts ...PrintOption) error {
+	p := printer{w: w, indent: "  "}
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	if e == nil {
+		p.line(0, "<nil>")
+		return p.err
+	}
+
+	p.failure(0, e)
+	return p.err
+}
+
+func (p *printer) line(depth int, format string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	if _, p.err = io.WriteString(p.w, strings.Repeat(p.indent, depth)); p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format+"\n", a...)
+}
+
+func (p *printer) failure(depth int, e error) {
+	// a Multi lists its members below, its headline is enough here
+	msg, _, _ := strings.Cut(e.Error(), "\n")
+	p.line(depth, "%s", msg)
+
+	if c, ok := Kind(e); ok {
+		p.line(depth+1, "category: %s", c.String())
+	}
+	p.line(depth+1, "severity: %s", SeverityOf(e))
+	if IsRetryable(e) {
+		p.line(depth+1, "retryable: true")
+	}
+
+	if frames, ok := StackTrace(e); ok && len(frames) > 0 {
+		if p.stack {
+			p.line(depth+1, "stack:")
+			for _, f := range frames {
+				p.line(depth+2, "%s (%s:%d)", f.Function, f.File, f.Line)
+			}
+		} else {
+			p.line(depth+1, "origin: %s (%s:%d)", frames[0].Function, frames[0].File, frames[0].Line)
+		}
+	}
+
+	if details := Details(e); len(details) > 0 {
+		keys := make([]string, 0, len(details))
+		for k := range details {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		p.line(depth+1, "details:")
+		for _, k := range keys {
+			p.line(depth+2, "%s: %v", k, details[k])
+		}
+	}
+
+	p.line(depth+1, "chain:")
+	p.chain(depth+2, e)
+}
+
+// chain writes one line per layer of e with the part of the message the
+// layer added.
+func (p *printer) chain(depth int, e error) {
+	for e != nil {
+		switch x := e.(type) {
+		case *annotation:
+			e = x.err
+			continue
+		case *netTimeout:
+			e = x.err
+			continue
+		case *redacted:
+			p.line(depth, "%s", x.Error())
+			return
+		case Category:
+			p.line(depth, "%s [%s]", x.Error(), x.String())
+			return
+		case *classified:
+			p.line(depth, "classified as %s", x.kind.String())
+			if x.cause != nil {
+				p.line(depth, "cause: %s", x.cause)
+			}
+			e = x.err
+			continue
+		case *RestAPI:
+			p.line(depth, "status %d: %s", x.StatusCode, x.Msg)
+			e = x.Err
+			continue
+		case *Multi:
+			p.line(depth, "%d failures:", len(x.Failures))
+			for _, m := range x.Failures {
+				if m != nil {
+					p.failure(depth+1, m)
+				}
+			}
+			return
+		case interface{ Unwrap() []error }:
+			members := x.Unwrap()
+			p.line(depth, "%d errors:", len(members))
+			for _, m := range members {
+				if m != nil {
+					p.failure(depth+1, m)
+				}
+			}
+			return
+		}
+
+		inner := next(e)
+		p.line(depth, "%s", layerMessage(e, inner))
+		e = inner
+	}
+}
+
+// layerMessage returns the part of the message of e added on top of inner
+func layerMessage(e, inner error) string {
+	if inner == nil {
+		return e.Error()
+	}
+	return strings.TrimSuffix(e.Error(), ": "+inner.Error())
+}
@@ -0,0 +1,60 @@
+package failure_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFprint(t *testing.T) {
+	err := failure.NotFound("user %d", 7)
+	err = failure.WithDetail(err, "user_id", 7)
+	err = failure.Wrap(err, "load profile")
+
+	var buf bytes.Buffer
+	require.NoError(t, failure.Fprint(&buf, err))
+
+	assert.Equal(t, `load profile: user 7: not found failure
+  category: not_found
+  severity: warning
+  details:
+    user_id: 7
+  chain:
+    load profile
+    user 7
+    not found failure [not_found]
+`, buf.String())
+}
+
+func TestFprint_StackAndMulti(t *testing.T) {
+	multi := failure.Append(nil,
+		failure.WithStack(failure.Timeout("db")),
+		failure.To(failure.KindSystem, errors.New("disk full"), "write"),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, failure.Fprint(&buf, multi, failure.PrintIndent("\t")))
+
+	out := buf.String()
+	assert.Contains(t, out, "\t\t2 failures:\n")
+	assert.Contains(t, out, "\t\t\tdb: timeout failure\n")
+	assert.Contains(t, out, "\t\t\t\torigin: github.com/rsb/failure_test.TestFprint_StackAndMulti")
+	assert.Contains(t, out, "\t\t\t\t\tclassified as system\n\t\t\t\t\tdisk full\n")
+
+	buf.Reset()
+	require.NoError(t, failure.Fprint(&buf, failure.WithStack(errors.New("foo")), failure.PrintStack()))
+	assert.True(t, strings.Contains(buf.String(), "  stack:\n"))
+
+	buf.Reset()
+	require.NoError(t, failure.Fprint(&buf, failure.Timeout("db"), failure.PrintIndent("%d ")))
+	assert.Contains(t, buf.String(), "\n%d severity: ")
+
+	buf.Reset()
+	require.NoError(t, failure.Fprint(&buf, nil))
+	assert.Equal(t, "<nil>\n", buf.String())
+}
@@ -0,0 +1,84 @@
+package failure
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// ProblemContentType is the media type of RFC 9457 Problem Details
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 Problem Details document. Category, Code and
+// InvalidParams are extension members, the latter lists the failed fields
+// of a RestAPI or Catalog.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Category      string         `json:"category,omitempty"`
+	Code          string         `json:"code,omitempty"`
+	InvalidParams []ProblemParam `json:"invalid-params,omitempty"`
+}
+
+// ProblemParam is a failed field listed in a Problem
+type ProblemParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// NewProblem builds the Problem for e the same way NewErrorResponse builds
+// its envelope, server errors only expose the status text. The type is
+// about:blank, set Type and Instance on the result to point at your own
+// documentation and at the request.
+func NewProblem(e error) Problem {
+	resp := NewErrorResponse(e)
+	p := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(resp.Status),
+		Status:   resp.Status,
+		Category: resp.Category,
+		Code:     resp.Code,
+	}
+	if resp.Message != p.Title {
+		p.Detail = resp.Message
+	}
+
+	for name, reason := range resp.Fields {
+		p.InvalidParams = append(p.InvalidParams, ProblemParam{Name: name, Reason: reason})
+	}
+	for group, fields := range resp.Errors {
+		for name, reason := range fields {
+			p.InvalidParams = append(p.InvalidParams, ProblemParam{Name: fieldPath(group, name), Reason: reason})
+		}
+	}
+	sort.Slice(p.InvalidParams, func(i, j int) bool {
+		return p.InvalidParams[i].Name < p.InvalidParams[j].Name
+	})
+
+	return p
+}
+
+// ToProblem returns the Problem describing r, see NewProblem
+func (r *RestAPI) ToProblem() Problem {
+	return NewProblem(r)
+}
+
+// ToProblem returns the Problem describing c with its failed fields as
+// invalid-params named "group.field", see NewProblem.
+func (c *Catalog) ToProblem() Problem {
+	return NewProblem(c)
+}
+
+// WriteProblem writes the Problem for e as an application/problem+json
+// response, with the same headers as WriteError.
+func WriteProblem(w http.ResponseWriter, e error) {
+	p := NewProblem(e)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	setResponseHeaders(w.Header(), e)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
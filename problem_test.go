@@ -0,0 +1,50 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblem(t *testing.T) {
+	r := failure.NewInvalidFields(map[string]string{"name": "is required", "age": "must be positive"}, "invalid user")
+	p := r.ToProblem()
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "Unprocessable Entity", p.Title)
+	assert.Equal(t, http.StatusUnprocessableEntity, p.Status)
+	assert.Equal(t, "invalid user", p.Detail)
+	assert.Equal(t, []failure.ProblemParam{
+		{Name: "age", Reason: "must be positive"},
+		{Name: "name", Reason: "is required"},
+	}, p.InvalidParams)
+
+	c := failure.NewCatalog("create_user", 0)
+	c.AddField("body", "email", "is required")
+	p = c.ToProblem()
+	assert.Equal(t, []failure.ProblemParam{{Name: "body.email", Reason: "is required"}}, p.InvalidParams)
+
+	p = failure.NewProblem(failure.System("dsn user:secret@db"))
+	assert.Equal(t, "Internal Server Error", p.Title)
+	assert.Empty(t, p.Detail)
+	assert.Equal(t, "system", p.Category)
+}
+
+func TestWriteProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	failure.WriteProblem(w, failure.NotFound("user 7"))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, failure.ProblemContentType, w.Header().Get("Content-Type"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "about:blank", doc["type"])
+	assert.Equal(t, "Not Found", doc["title"])
+	assert.Equal(t, float64(404), doc["status"])
+	assert.NotContains(t, doc, "invalid-params")
+}
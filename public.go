@@ -0,0 +1,25 @@
+package failure
+
+type publicMessageKey struct{}
+
+// WithPublicMessage returns e carrying a message that is safe to show to
+// clients, while e itself keeps the detailed chain for logs. It is used as
+// the message of the ErrorResponse and Problem written for e, whatever its
+// status:
+//
+//	return failure.WithPublicMessage(
+//		failure.Wrap(err, "charge card %s", card.ID), "the payment was declined")
+func WithPublicMessage(e error, msg string) error {
+	return annotate(e, publicMessageKey{}, msg)
+}
+
+// PublicMessage returns the message set with WithPublicMessage, the
+// outermost one when it was set more than once.
+func PublicMessage(e error) (string, bool) {
+	v, ok := lookup(e, publicMessageKey{})
+	if !ok {
+		return "", false
+	}
+
+	return v.(string), true
+}
@@ -0,0 +1,32 @@
+package failure_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicMessage(t *testing.T) {
+	err := failure.ToSystem(errors.New("card 4242 declined by gateway"), "charge")
+	_, ok := failure.PublicMessage(err)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusText(http.StatusInternalServerError), failure.NewErrorResponse(err).Message)
+
+	err = failure.WithPublicMessage(err, "the payment was declined")
+	err = failure.Wrap(err, "checkout")
+
+	msg, ok := failure.PublicMessage(err)
+	assert.True(t, ok)
+	assert.Equal(t, "the payment was declined", msg)
+	assert.Contains(t, err.Error(), "card 4242")
+	assert.True(t, failure.IsSystem(err))
+
+	assert.Equal(t, "the payment was declined", failure.NewErrorResponse(err).Message)
+	assert.Equal(t, "the payment was declined", failure.NewProblem(err).Detail)
+
+	bad := failure.WithPublicMessage(failure.BadRequest("field x of y"), "invalid order")
+	assert.Equal(t, "invalid order", failure.NewErrorResponse(bad).Message)
+}
@@ -0,0 +1,80 @@
+package failure
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Pools backing the Acquire functions, validation builds these values on
+// every request so reusing them takes pressure off the garbage collector.
+var (
+	catalogPool = sync.Pool{New: func() interface{} {
+		return &Catalog{Groups: map[string]*FieldGroup{}}
+	}}
+	restAPIPool = sync.Pool{New: func() interface{} {
+		return new(RestAPI)
+	}}
+)
+
+// AcquireCatalog is NewCatalog drawing from a pool. Hand the catalog back
+// with Release once the response is written:
+//
+//	c := failure.AcquireCatalog("create_user", 0)
+//	defer c.Release()
+//
+// Catalogs that are never released are simply garbage collected, which is
+// the right call whenever the catalog may outlive the request, for
+// instance when it is returned as an error to a caller you don't control.
+func AcquireCatalog(key string, status int) *Catalog {
+	if status == 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	c := catalogPool.Get().(*Catalog)
+	c.Key, c.Status = key, status
+	if c.Groups == nil {
+		c.Groups = map[string]*FieldGroup{}
+	}
+
+	return c
+}
+
+// Release returns c to its pool, it must not be used afterwards. Release is
+// only safe once nothing references c anymore, that is once the response
+// is written and no error chain, log entry or reporter queue holds it or an
+// error wrapping it. Its groups and fields are left to the garbage
+// collector, so those read from Groups, and the messages returned by
+// AllFailures and Error, stay valid.
+func (c *Catalog) Release() {
+	if c == nil {
+		return
+	}
+
+	for name := range c.Groups {
+		delete(c.Groups, name)
+	}
+	c.order = c.order[:0]
+	c.Key, c.Status = "", 0
+
+	catalogPool.Put(c)
+}
+
+// AcquireRestAPI returns a RestAPI drawn from a pool, see Release
+func AcquireRestAPI(status int, msg string, fields map[string]string, err error) *RestAPI {
+	r := restAPIPool.Get().(*RestAPI)
+	r.StatusCode, r.Msg, r.Fields, r.Err = status, msg, fields, err
+
+	return r
+}
+
+// Release returns r to its pool, it must not be used afterwards, so only
+// release a RestAPI once it has been written to the client and is no longer
+// referenced by any error chain.
+func (r *RestAPI) Release() {
+	if r == nil {
+		return
+	}
+
+	*r = RestAPI{}
+	restAPIPool.Put(r)
+}
@@ -0,0 +1,58 @@
+package failure_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireCatalog(t *testing.T) {
+	c := failure.AcquireCatalog("create_user", 0)
+	assert.Equal(t, http.StatusUnprocessableEntity, c.Status)
+
+	c.AddField("body", "email", "is required")
+	require.Error(t, c.ErrorOrNil())
+	failures := c.AllFailures()
+	email := c.Groups["body"].Fields["email"]
+	c.Release()
+
+	assert.Equal(t, map[string]map[string]string{"body": {"email": "is required"}}, failures)
+	assert.Equal(t, "is required", email.Msg)
+
+	c = failure.AcquireCatalog("update_user", http.StatusBadRequest)
+	defer c.Release()
+	assert.Equal(t, "update_user", c.Key)
+	assert.Zero(t, c.ErrorCount())
+}
+
+func TestAcquireRestAPI(t *testing.T) {
+	r := failure.AcquireRestAPI(http.StatusBadRequest, "missing id", nil, failure.KindBadRequest)
+	assert.True(t, failure.IsBadRequest(r))
+	r.Release()
+}
+
+func validateUser(c *failure.Catalog) {
+	c.Check(false, "body", "email", "is required")
+	c.Check(false, "body", "age", "must be at least 18")
+	c.Check(false, "query", "tenant", "is unknown")
+	_ = c.ErrorOrNil()
+}
+
+func BenchmarkCatalog(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		validateUser(failure.NewCatalog("create_user", 0))
+	}
+}
+
+func BenchmarkCatalogPooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c := failure.AcquireCatalog("create_user", 0)
+		validateUser(c)
+		c.Release()
+	}
+}
@@ -0,0 +1,124 @@
+package failure
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedMask replaces the values masked by Redact and DefaultRedactor
+const RedactedMask = "[REDACTED]"
+
+// Redactor masks sensitive values, such as tokens, emails or connection
+// strings, in the message of an error.
+type Redactor interface {
+	Redact(msg string) string
+}
+
+// RedactorFunc adapts a function to the Redactor interface
+type RedactorFunc func(msg string) string
+
+// Redact calls fn(msg)
+func (fn RedactorFunc) Redact(msg string) string {
+	return fn(msg)
+}
+
+var credentials = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(://[^:/@\s]*:)[^@\s]+@`), "${1}" + RedactedMask + "@"},
+	{regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api[_-]?key)=[^\s&;]+`), "${1}=" + RedactedMask},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`), "${1} " + RedactedMask},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), RedactedMask},
+}
+
+// DefaultRedactor masks the password of connection strings and URLs,
+// password, secret, token and api key parameters, bearer and basic
+// credentials and email addresses:
+//
+//	failure.SetRedactor(failure.DefaultRedactor)
+var DefaultRedactor Redactor = RedactorFunc(func(msg string) string {
+	for _, c := range credentials {
+		msg = c.re.ReplaceAllString(msg, c.repl)
+	}
+
+	return msg
+})
+
+var redaction = struct {
+	sync.RWMutex
+	redactor Redactor
+}{}
+
+// SetRedactor sets the Redactor applied to the message of the errors
+// classified by the ToX constructors and To, and to the message of Redact,
+// so raw driver or client errors don't leak credentials into logs and
+// responses. nil removes it. Errors classified before it is set are left as
+// they are.
+func SetRedactor(r Redactor) {
+	redaction.Lock()
+	defer redaction.Unlock()
+	redaction.redactor = r
+}
+
+func currentRedactor() Redactor {
+	redaction.RLock()
+	defer redaction.RUnlock()
+	return redaction.redactor
+}
+
+func redactMessage(msg string) string {
+	if r := currentRedactor(); r != nil {
+		return r.Redact(msg)
+	}
+
+	return msg
+}
+
+// Redact returns err with every occurrence of secrets in its message
+// replaced by RedactedMask and the Redactor applied, see SetRedactor, for
+// errors wrapped with Wrap rather than classified:
+//
+//	return failure.Wrap(failure.Redact(err, cfg.Password), "connect to %s", cfg.Host)
+//
+// It still matches what err matches with errors.Is and errors.As, but
+// MessageChain, Fprint and ToDOT stop at the redacted message. nil is
+// returned when err is nil.
+func Redact(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &redacted{err: err, secrets: secrets}
+}
+
+// redacted is an error with sensitive values masked in its message
+type redacted struct {
+	err     error
+	secrets []string
+}
+
+func (r *redacted) Error() string {
+	msg := r.err.Error()
+	for _, s := range r.secrets {
+		if s != "" {
+			msg = strings.ReplaceAll(msg, s, RedactedMask)
+		}
+	}
+
+	return redactMessage(msg)
+}
+
+func (r *redacted) Unwrap() error {
+	return r.err
+}
+
+// redactable returns e masked by the Redactor when one is set
+func redactable(e error) error {
+	if currentRedactor() == nil {
+		return e
+	}
+
+	return &redacted{err: e}
+}
@@ -0,0 +1,188 @@
+package failure
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Reporter sends failures to an error tracking backend such as Sentry or
+// Rollbar.
+type Reporter interface {
+	Report(ctx context.Context, err error)
+}
+
+// ReporterFunc adapts a function to the Reporter interface
+type ReporterFunc func(ctx context.Context, err error)
+
+// Report calls fn(ctx, err)
+func (fn ReporterFunc) Report(ctx context.Context, err error) {
+	fn(ctx, err)
+}
+
+// Multiplexer is a Reporter that fans every report out to all of its
+// reporters, in order.
+type Multiplexer []Reporter
+
+// Report sends err to every reporter
+func (m Multiplexer) Report(ctx context.Context, err error) {
+	for _, r := range m {
+		r.Report(ctx, err)
+	}
+}
+
+type asyncReport struct {
+	ctx context.Context
+	err error
+}
+
+// AsyncReporter buffers reports and hands them to another Reporter from a
+// background goroutine, so a slow backend never blocks the caller. When the
+// buffer is full, or once it is closed, reports are dropped rather than
+// waited on.
+type AsyncReporter struct {
+	next  Reporter
+	queue chan asyncReport
+	done  chan struct{}
+	// mutex guards closed, Report holds it for reading while it sends so
+	// Close never closes the queue under it
+	mutex   sync.RWMutex
+	closed  bool
+	dropped int64
+}
+
+// NewAsyncReporter starts an AsyncReporter holding at most size pending
+// reports for next.
+func NewAsyncReporter(next Reporter, size int) *AsyncReporter {
+	a := AsyncReporter{
+		next:  next,
+		queue: make(chan asyncReport, size),
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		defer close(a.done)
+		for r := range a.queue {
+			a.next.Report(r.ctx, r.err)
+		}
+	}()
+
+	return &a
+}
+
+// Report queues err. The context handed to the backend keeps the values of
+// ctx but not its cancellation, since the request that produced the failure
+// has usually ended by the time it is reported, a nil ctx stands for
+// context.Background. Reports arriving after Close are dropped.
+func (a *AsyncReporter) Report(ctx context.Context, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if a.closed {
+		atomic.AddInt64(&a.dropped, 1)
+		return
+	}
+
+	select {
+	case a.queue <- asyncReport{ctx: context.WithoutCancel(ctx), err: err}:
+	default:
+		atomic.AddInt64(&a.dropped, 1)
+	}
+}
+
+// Dropped returns the number of reports lost because the buffer was full or
+// they arrived after Close
+func (a *AsyncReporter) Dropped() int {
+	return int(atomic.LoadInt64(&a.dropped))
+}
+
+// Close stops accepting reports and waits for the pending ones to be sent.
+// It can be called more than once.
+func (a *AsyncReporter) Close() {
+	a.mutex.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mutex.Unlock()
+
+	<-a.done
+}
+
+var reporting = struct {
+	sync.RWMutex
+	seq        int
+	reporters  map[int]Reporter
+	order      []int
+	threshold  Severity
+	stampBuild bool
+}{
+	reporters: map[int]Reporter{},
+	threshold: SeverityError,
+}
+
+// RegisterReporter adds r to the reporters used by Report. The returned
+// function removes it again.
+func RegisterReporter(r Reporter) func() {
+	reporting.Lock()
+	defer reporting.Unlock()
+
+	reporting.seq++
+	id := reporting.seq
+	reporting.reporters[id] = r
+	reporting.order = append(reporting.order, id)
+
+	return func() {
+		reporting.Lock()
+		defer reporting.Unlock()
+
+		delete(reporting.reporters, id)
+		for i, x := range reporting.order {
+			if x == id {
+				reporting.order = append(reporting.order[:i:i], reporting.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// SetReportThreshold sets the lowest severity sent by Report, it defaults
+// to SeverityError.
+func SetReportThreshold(s Severity) {
+	reporting.Lock()
+	defer reporting.Unlock()
+	reporting.threshold = s
+}
+
+// Report runs the enrichers on err, see AddEnricher, and sends it to every
+// registered reporter when its severity is at or above the report
+// threshold. nil errors are ignored.
+func Report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	err = Enrich(ctx, err)
+
+	reporting.RLock()
+	if SeverityOf(err) < reporting.threshold {
+		reporting.RUnlock()
+		return
+	}
+	m := make(Multiplexer, 0, len(reporting.order))
+	for _, id := range reporting.order {
+		m = append(m, reporting.reporters[id])
+	}
+	stampBuild := reporting.stampBuild
+	reporting.RUnlock()
+
+	if stampBuild {
+		err = WithBuildInfo(err)
+	}
+
+	m.Report(ctx, err)
+}
@@ -0,0 +1,137 @@
+package failure_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mutex sync.Mutex
+	errs  []error
+}
+
+func (r *recorder) Report(_ context.Context, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *recorder) reported() []error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func TestReport(t *testing.T) {
+	a, b := &recorder{}, &recorder{}
+	removeA := failure.RegisterReporter(a)
+	removeB := failure.RegisterReporter(b)
+	defer removeB()
+
+	ctx := context.Background()
+	failure.Report(ctx, failure.System("db is down"))
+	failure.Report(ctx, failure.NotFound("user 1"))
+	failure.Report(ctx, nil)
+
+	require.Len(t, a.reported(), 1)
+	require.Len(t, b.reported(), 1)
+	assert.True(t, failure.IsSystem(a.reported()[0]))
+
+	removeA()
+	failure.SetReportThreshold(failure.SeverityWarning)
+	defer failure.SetReportThreshold(failure.SeverityError)
+
+	failure.Report(ctx, failure.NotFound("user 1"))
+	assert.Len(t, a.reported(), 1)
+	assert.Len(t, b.reported(), 2)
+}
+
+func TestMultiplexer(t *testing.T) {
+	a := &recorder{}
+	var called bool
+	m := failure.Multiplexer{a, failure.ReporterFunc(func(context.Context, error) {
+		called = true
+	})}
+
+	m.Report(context.Background(), failure.System("foo"))
+	assert.Len(t, a.reported(), 1)
+	assert.True(t, called)
+}
+
+type ctxKey struct{}
+
+func TestAsyncReporter(t *testing.T) {
+	a := &recorder{}
+	var value interface{}
+	var canceled error
+	async := failure.NewAsyncReporter(failure.Multiplexer{a, failure.ReporterFunc(func(ctx context.Context, _ error) {
+		value = ctx.Value(ctxKey{})
+		canceled = ctx.Err()
+	})}, 10)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request-1"))
+	cancel()
+
+	async.Report(ctx, failure.System("one"))
+	async.Report(ctx, failure.System("two"))
+	async.Close()
+
+	assert.Len(t, a.reported(), 2)
+	assert.Equal(t, "request-1", value)
+	assert.NoError(t, canceled)
+	assert.Equal(t, 0, async.Dropped())
+}
+
+func TestAsyncReporter_Dropped(t *testing.T) {
+	release := make(chan struct{})
+	async := failure.NewAsyncReporter(failure.ReporterFunc(func(context.Context, error) {
+		<-release
+	}), 1)
+
+	for i := 0; i < 5; i++ {
+		async.Report(context.Background(), failure.System("foo"))
+	}
+	close(release)
+	async.Close()
+
+	assert.GreaterOrEqual(t, async.Dropped(), 3)
+}
+
+func TestAsyncReporter_AfterClose(t *testing.T) {
+	var mutex sync.Mutex
+	var got []error
+	async := failure.NewAsyncReporter(failure.ReporterFunc(func(_ context.Context, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		got = append(got, err)
+	}), 10)
+
+	async.Report(nil, failure.System("before"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			async.Report(context.Background(), failure.System("racing"))
+		}()
+	}
+	async.Close()
+	wg.Wait()
+
+	assert.NotPanics(t, func() {
+		async.Report(context.Background(), failure.System("after"))
+	})
+	async.Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 22, len(got)+async.Dropped())
+	assert.NotEmpty(t, got)
+	assert.Equal(t, "before: "+failure.SystemMsg, got[0].Error())
+}
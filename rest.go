@@ -4,12 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type RestAPI struct {
 	StatusCode int
 	Msg        string
 	Fields     map[string]string
+	// Headers are added to the response written for the failure
+	Headers http.Header
+	// RetryAfter is sent as the Retry-After header when positive, see
+	// RetryAfter
+	RetryAfter time.Duration
 	Err        error
 }
 
@@ -17,19 +23,25 @@ func (r *RestAPI) Error() string {
 	return r.Err.Error()
 }
 
+// Unwrap returns Err, so the category of the response matches errors.Is and
+// the IsX predicates the same way it does Kind.
+func (r *RestAPI) Unwrap() error {
+	return r.Err
+}
+
 func NewInvalidFields(f map[string]string, msg string, a ...interface{}) *RestAPI {
 	r := RestAPI{
 		StatusCode: http.StatusUnprocessableEntity,
 		Msg:        fmt.Sprintf(msg, a...),
 		Fields:     f,
-		Err:        invalidAPIFieldsErr,
+		Err:        KindInvalidAPIFields,
 	}
 
 	return &r
 }
 
 func InvalidFields(f map[string]string, msg string, a ...interface{}) error {
-	return NewInvalidFields(f, msg, a...)
+	return captureStack(NewInvalidFields(f, msg, a...))
 }
 
 func GetInvalidFields(e error) (map[string]string, bool) {
@@ -55,13 +67,13 @@ func NewBadRequest(msg string, a ...interface{}) *RestAPI {
 	r := RestAPI{
 		StatusCode: http.StatusBadRequest,
 		Msg:        fmt.Sprintf(msg, a...),
-		Err:        badRequestErr,
+		Err:        KindBadRequest,
 	}
 	return &r
 }
 
 func BadRequest(msg string, a ...interface{}) error {
-	return NewBadRequest(msg, a...)
+	return captureStack(NewBadRequest(msg, a...))
 }
 
 func ToBadRequest(e error, msg string, a ...interface{}) error {
@@ -70,7 +82,7 @@ func ToBadRequest(e error, msg string, a ...interface{}) error {
 		Msg:        fmt.Sprintf(msg, a...),
 		Err:        e,
 	}
-	return &r
+	return captureStack(&r)
 }
 
 func IsBadRequest(e error) bool {
@@ -83,6 +95,34 @@ func IsBadRequest(e error) bool {
 	return false
 }
 
+// NewTooManyRequests creates a 429 response classified as RateLimited,
+// telling the client to wait retryAfter before trying again.
+func NewTooManyRequests(retryAfter time.Duration, msg string, a ...interface{}) *RestAPI {
+	r := RestAPI{
+		StatusCode: http.StatusTooManyRequests,
+		Msg:        fmt.Sprintf(msg, a...),
+		RetryAfter: retryAfter,
+		Err:        KindRateLimited,
+	}
+	return &r
+}
+
+// TooManyRequests is NewTooManyRequests as an error
+func TooManyRequests(retryAfter time.Duration, msg string, a ...interface{}) error {
+	return captureStack(NewTooManyRequests(retryAfter, msg, a...))
+}
+
+// IsTooManyRequests reports whether e is a RestAPI answered with 429
+func IsTooManyRequests(e error) bool {
+	var r *RestAPI
+
+	if errors.As(e, &r) {
+		return r.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
 func RestStatusCode(e error) (int, bool) {
 	var r *RestAPI
 
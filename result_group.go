@@ -0,0 +1,60 @@
+package failure
+
+import "sync"
+
+// ResultGroup is a Group whose functions also produce a value. The zero
+// value is ready to use.
+type ResultGroup[T any] struct {
+	mutex   sync.Mutex
+	wg      sync.WaitGroup
+	results []groupResult[T]
+	err     *Multi
+}
+
+type groupResult[T any] struct {
+	value T
+	ok    bool
+}
+
+// Go calls the given function in a new goroutine. Its value is kept when it
+// succeeds, otherwise its error is added to the multierror returned by Wait.
+func (g *ResultGroup[T]) Go(f func() (T, error)) {
+	g.mutex.Lock()
+	i := len(g.results)
+	g.results = append(g.results, groupResult[T]{})
+	g.mutex.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		v, err := f()
+
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		if err != nil {
+			g.err = Append(g.err, err)
+			return
+		}
+		g.results[i] = groupResult[T]{value: v, ok: true}
+	}()
+}
+
+// Wait blocks until all function calls from the Go method have returned, then
+// returns the values of the successful ones, in the order they were submitted,
+// and the Multi of the failed ones, nil when none failed.
+func (g *ResultGroup[T]) Wait() ([]T, *Multi) {
+	g.wg.Wait()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	values := make([]T, 0, len(g.results))
+	for _, r := range g.results {
+		if r.ok {
+			values = append(values, r.value)
+		}
+	}
+
+	return values, g.err
+}
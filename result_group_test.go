@@ -0,0 +1,40 @@
+package failure_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultGroup(t *testing.T) {
+	var g failure.ResultGroup[int]
+	for i := 0; i < 10; i++ {
+		i := i
+		g.Go(func() (int, error) {
+			// finish in reverse order to prove submission order is kept
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+			if i%3 == 0 {
+				return 0, failure.Timeout("task %d", i)
+			}
+			return i, nil
+		})
+	}
+
+	values, err := g.Wait()
+	assert.Equal(t, []int{1, 2, 4, 5, 7, 8}, values)
+	require.NotNil(t, err)
+	assert.Len(t, err.Failures, 4)
+	assert.True(t, failure.IsTimeout(err))
+}
+
+func TestResultGroup_NoFailures(t *testing.T) {
+	var g failure.ResultGroup[string]
+	g.Go(func() (string, error) { return "a", nil })
+
+	values, err := g.Wait()
+	assert.Equal(t, []string{"a"}, values)
+	assert.Nil(t, err)
+}
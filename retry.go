@@ -0,0 +1,180 @@
+package failure
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type retryableKey struct{}
+
+// MarkRetryable flags e as transient, trying the operation again may succeed
+func MarkRetryable(e error) error {
+	return annotate(e, retryableKey{}, true)
+}
+
+// MarkPermanent flags e as permanent, trying the operation again will fail
+// the same way.
+func MarkPermanent(e error) error {
+	return annotate(e, retryableKey{}, false)
+}
+
+// IsRetryable reports whether e is transient. An explicit MarkRetryable or
+// MarkPermanent wins, otherwise the default of its category is used, Timeout
+// for example is retryable. Errors that are not failures are not retryable.
+func IsRetryable(e error) bool {
+	if v, ok := lookup(e, retryableKey{}); ok {
+		return v.(bool)
+	}
+
+	if c, ok := Kind(e); ok {
+		info, _ := categoryInfoOf(c)
+		return info.retryable
+	}
+
+	return false
+}
+
+type retryAfterKey struct{}
+
+// WithRetryAfter returns e carrying how long callers should wait before
+// trying again. It is sent as the Retry-After header of HTTP responses.
+func WithRetryAfter(e error, d time.Duration) error {
+	return annotate(e, retryAfterKey{}, d)
+}
+
+// RetryAfter returns the wait suggested with WithRetryAfter, or else the
+// RetryAfter of a RestAPI in the chain of e.
+func RetryAfter(e error) (time.Duration, bool) {
+	if v, ok := lookup(e, retryAfterKey{}); ok {
+		return v.(time.Duration), true
+	}
+
+	var r *RestAPI
+	if errors.As(e, &r) && r.RetryAfter > 0 {
+		return r.RetryAfter, true
+	}
+
+	return 0, false
+}
+
+type safeToRetryKey struct{}
+
+type attemptsKey struct{}
+
+// MarkSafeToRetry flags the operation that produced e as idempotent, running
+// it again has no additional side effects. This is independent of whether
+// the failure is transient, see MarkRetryable.
+func MarkSafeToRetry(e error) error {
+	return annotate(e, safeToRetryKey{}, true)
+}
+
+// MarkUnsafeToRetry flags the operation that produced e as not idempotent,
+// a retry could apply its side effects twice, e.g. a POST that timed out
+// after the server processed it.
+func MarkUnsafeToRetry(e error) error {
+	return annotate(e, safeToRetryKey{}, false)
+}
+
+// IsSafeToRetry reports whether e was explicitly marked safe to retry
+func IsSafeToRetry(e error) bool {
+	v, ok := lookup(e, safeToRetryKey{})
+	return ok && v.(bool)
+}
+
+// isUnsafeToRetry reports whether e was explicitly marked unsafe to retry
+func isUnsafeToRetry(e error) bool {
+	v, ok := lookup(e, safeToRetryKey{})
+	return ok && !v.(bool)
+}
+
+// Attempts returns the number of calls Retry made before giving up
+func Attempts(e error) (int, bool) {
+	v, ok := lookup(e, attemptsKey{})
+	if !ok {
+		return 0, false
+	}
+
+	return v.(int), true
+}
+
+// RetryPolicy configures Retry
+type RetryPolicy struct {
+	// Attempts is the maximum number of calls, including the first one
+	Attempts int
+	// Backoff returns how long to wait before the nth retry, starting at 1.
+	// It defaults to ExponentialBackoff(100*time.Millisecond, 10*time.Second).
+	Backoff func(n int) time.Duration
+	// Retryable decides whether a failure is transient, it defaults to
+	// IsRetryable.
+	Retryable Matcher
+	// Write marks an operation that is not naturally idempotent. Its
+	// failures are only retried when they are both transient and marked
+	// with MarkSafeToRetry.
+	Write bool
+}
+
+// ExponentialBackoff doubles the wait after every retry starting at base,
+// never waiting longer than max.
+func ExponentialBackoff(base, max time.Duration) func(n int) time.Duration {
+	return func(n int) time.Duration {
+		d := base
+		for i := 1; i < n && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// Retry calls fn until it succeeds, the policy says its failure should not
+// be retried, the attempts run out or ctx is done. The last failure is
+// returned annotated with the number of attempts, see Attempts. Failures
+// marked with MarkUnsafeToRetry are never retried, neither are soft ones
+// since the caller is going to degrade rather than wait, see MarkSoft.
+func Retry(ctx context.Context, p RetryPolicy, fn func(ctx context.Context) error) error {
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(100*time.Millisecond, 10*time.Second)
+	}
+
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	var err error
+	attempt := 0
+	for {
+		attempt++
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		if attempt >= p.Attempts || !p.shouldRetry(err, retryable) {
+			return annotate(err, attemptsKey{}, attempt)
+		}
+
+		t := time.NewTimer(backoff(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return annotate(WrapContext(ctx, err, "retry abandoned"), attemptsKey{}, attempt)
+		case <-t.C:
+		}
+	}
+}
+
+func (p RetryPolicy) shouldRetry(e error, retryable Matcher) bool {
+	if isUnsafeToRetry(e) || IsSoft(e) || !retryable(e) {
+		return false
+	}
+
+	if p.Write {
+		return IsSafeToRetry(e)
+	}
+
+	return true
+}
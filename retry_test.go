@@ -0,0 +1,140 @@
+package failure_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, failure.IsRetryable(failure.Timeout("slow")))
+	assert.False(t, failure.IsRetryable(failure.Validation("bad")))
+	assert.False(t, failure.IsRetryable(errors.New("foo")))
+
+	err := failure.MarkRetryable(failure.System("connection reset"))
+	assert.True(t, failure.IsRetryable(err))
+	assert.True(t, failure.IsRetryable(failure.Wrap(err, "query")))
+	assert.True(t, failure.IsSystem(err))
+
+	err = failure.MarkPermanent(failure.Timeout("budget spent"))
+	assert.False(t, failure.IsRetryable(err))
+	assert.True(t, failure.IsTimeout(err))
+}
+
+func TestMarkSafeToRetry(t *testing.T) {
+	err := failure.MarkSafeToRetry(failure.Timeout("slow"))
+	assert.True(t, failure.IsSafeToRetry(err))
+	assert.True(t, failure.IsRetryable(err))
+
+	err = failure.MarkUnsafeToRetry(err)
+	assert.False(t, failure.IsSafeToRetry(err))
+	assert.True(t, failure.IsRetryable(err))
+
+	assert.False(t, failure.IsSafeToRetry(failure.Timeout("slow")))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := failure.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, b(1))
+	assert.Equal(t, 20*time.Millisecond, b(2))
+	assert.Equal(t, 40*time.Millisecond, b(3))
+	assert.Equal(t, 50*time.Millisecond, b(4))
+	assert.Equal(t, 50*time.Millisecond, b(40))
+}
+
+func noWait(int) time.Duration { return 0 }
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	err := failure.Retry(ctx, failure.RetryPolicy{Attempts: 5, Backoff: noWait}, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return failure.Timeout("slow")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = failure.Retry(ctx, failure.RetryPolicy{Attempts: 3, Backoff: noWait}, func(context.Context) error {
+		calls++
+		return failure.Timeout("slow")
+	})
+	assert.True(t, failure.IsTimeout(err))
+	assert.Equal(t, 3, calls)
+	n, ok := failure.Attempts(err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+
+	calls = 0
+	err = failure.Retry(ctx, failure.RetryPolicy{Attempts: 3, Backoff: noWait}, func(context.Context) error {
+		calls++
+		return failure.Validation("bad")
+	})
+	assert.True(t, failure.IsValidation(err))
+	assert.Equal(t, 1, calls)
+
+	_, ok = failure.Attempts(errors.New("foo"))
+	assert.False(t, ok)
+}
+
+func TestRetry_Write(t *testing.T) {
+	ctx := context.Background()
+	policy := failure.RetryPolicy{Attempts: 3, Backoff: noWait, Write: true}
+
+	calls := 0
+	_ = failure.Retry(ctx, policy, func(context.Context) error {
+		calls++
+		return failure.Timeout("POST /orders timed out")
+	})
+	assert.Equal(t, 1, calls, "a transient failure alone is not enough for a write")
+
+	calls = 0
+	_ = failure.Retry(ctx, policy, func(context.Context) error {
+		calls++
+		return failure.MarkSafeToRetry(failure.Timeout("PUT /orders/1 timed out"))
+	})
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	_ = failure.Retry(ctx, failure.RetryPolicy{Attempts: 3, Backoff: noWait}, func(context.Context) error {
+		calls++
+		return failure.MarkUnsafeToRetry(failure.Timeout("POST /orders timed out"))
+	})
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_Matcher(t *testing.T) {
+	calls := 0
+	policy := failure.RetryPolicy{
+		Attempts:  4,
+		Backoff:   noWait,
+		Retryable: failure.MessageMatcher("*connection reset*"),
+	}
+	_ = failure.Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return errors.New("read: connection reset by peer")
+	})
+	assert.Equal(t, 4, calls)
+}
+
+func TestRetry_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := failure.RetryPolicy{Attempts: 10, Backoff: func(int) time.Duration { return time.Hour }}
+
+	err := failure.Retry(ctx, policy, func(context.Context) error {
+		cancel()
+		return failure.Timeout("slow")
+	})
+	assert.True(t, failure.IsCanceled(err))
+	assert.True(t, failure.IsTimeout(err))
+	n, _ := failure.Attempts(err)
+	assert.Equal(t, 1, n)
+}
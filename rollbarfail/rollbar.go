@@ -0,0 +1,81 @@
+// Package rollbarfail reports failures to Rollbar.
+//
+// The package does not import the Rollbar SDK, a *rollbar.Client from
+// github.com/rollbar/rollbar-go satisfies the Client interface.
+package rollbarfail
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rsb/failure"
+)
+
+const (
+	// CategoryKey is the custom data key holding the failure category
+	CategoryKey = "failure_category"
+	// FingerprintKey is the custom data key holding the failure fingerprint
+	FingerprintKey = "failure_fingerprint"
+)
+
+// Client is the part of the Rollbar client used by the Reporter
+type Client interface {
+	ErrorWithStackSkipWithExtrasAndContext(ctx context.Context, level string, err error, skip int, extras map[string]interface{})
+}
+
+// Reporter is a failure.Reporter sending failures to Rollbar
+type Reporter struct {
+	client Client
+}
+
+// NewReporter creates a Reporter for client. Install Transform on the
+// client so Rollbar groups items by the failure fingerprint.
+func NewReporter(client Client) *Reporter {
+	return &Reporter{client: client}
+}
+
+// Report sends err with its log level, see failure.LevelFor, mapped to a
+// Rollbar level and its category and fingerprint as custom data.
+func (r *Reporter) Report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	extras := map[string]interface{}{
+		FingerprintKey: failure.Fingerprint(err),
+	}
+	if c, ok := failure.Kind(err); ok {
+		extras[CategoryKey] = c.String()
+	}
+
+	r.client.ErrorWithStackSkipWithExtrasAndContext(ctx, Level(failure.LevelFor(err)), err, 2, extras)
+}
+
+// Level maps a log level onto a Rollbar level
+func Level(l slog.Level) string {
+	switch {
+	case l >= failure.LevelCritical:
+		return "critical"
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warning"
+	case l >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// Transform moves the failure fingerprint from the custom data to the item
+// fingerprint. It is meant to be installed with rollbar.SetTransform.
+func Transform(data map[string]interface{}) {
+	custom, ok := data["custom"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if fp, ok := custom[FingerprintKey].(string); ok && fp != "" {
+		data["fingerprint"] = fp
+	}
+}
@@ -0,0 +1,74 @@
+package rollbarfail_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/rsb/failure/rollbarfail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	level  string
+	err    error
+	extras map[string]interface{}
+}
+
+type fakeClient struct {
+	items []item
+}
+
+func (c *fakeClient) ErrorWithStackSkipWithExtrasAndContext(_ context.Context, level string, err error, _ int, extras map[string]interface{}) {
+	c.items = append(c.items, item{level: level, err: err, extras: extras})
+}
+
+func TestReporter(t *testing.T) {
+	client := &fakeClient{}
+	r := rollbarfail.NewReporter(client)
+
+	err := failure.Panic("nil map")
+	r.Report(context.Background(), err)
+	r.Report(context.Background(), nil)
+
+	require.Len(t, client.items, 1)
+	got := client.items[0]
+	assert.Equal(t, "critical", got.level)
+	assert.Equal(t, err, got.err)
+	assert.Equal(t, "panic", got.extras[rollbarfail.CategoryKey])
+	assert.Equal(t, failure.Fingerprint(err), got.extras[rollbarfail.FingerprintKey])
+
+	var _ failure.Reporter = r
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, "debug", rollbarfail.Level(slog.LevelDebug))
+	assert.Equal(t, "info", rollbarfail.Level(slog.LevelInfo))
+	assert.Equal(t, "warning", rollbarfail.Level(slog.LevelWarn))
+	assert.Equal(t, "error", rollbarfail.Level(slog.LevelError))
+	assert.Equal(t, "critical", rollbarfail.Level(failure.LevelCritical))
+}
+
+func TestReporter_LevelOverride(t *testing.T) {
+	defer failure.SetCategoryLevels(nil)
+	failure.SetCategoryLevels(map[failure.Category]slog.Level{failure.KindNotFound: slog.LevelDebug})
+
+	client := &fakeClient{}
+	rollbarfail.NewReporter(client).Report(context.Background(), failure.NotFound("user"))
+	require.Len(t, client.items, 1)
+	assert.Equal(t, "debug", client.items[0].level)
+}
+
+func TestTransform(t *testing.T) {
+	data := map[string]interface{}{
+		"custom": map[string]interface{}{rollbarfail.FingerprintKey: "abc"},
+	}
+	rollbarfail.Transform(data)
+	assert.Equal(t, "abc", data["fingerprint"])
+
+	empty := map[string]interface{}{}
+	rollbarfail.Transform(empty)
+	assert.NotContains(t, empty, "fingerprint")
+}
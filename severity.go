@@ -0,0 +1,60 @@
+package failure
+
+// Severity describes how serious a failure is. Every category has a default
+// severity which can be overridden with WithSeverity.
+type Severity int
+
+const (
+	SeverityDebug Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+type severityKey struct{}
+
+// String returns the lower case name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// WithSeverity returns e with its severity set to s
+func WithSeverity(e error, s Severity) error {
+	return annotate(e, severityKey{}, s)
+}
+
+// SeverityOf returns the severity set with WithSeverity, falling back to the
+// default of the outermost category and finally to SeverityError for errors
+// that are not failures. During shutdown expected teardown failures are
+// capped at SeverityWarning, see BeginShutdown.
+func SeverityOf(e error) Severity {
+	return downgradeSeverity(e, severityOf(e))
+}
+
+func severityOf(e error) Severity {
+	if v, ok := lookup(e, severityKey{}); ok {
+		return v.(Severity)
+	}
+
+	if c, ok := Kind(e); ok {
+		if info, ok := categoryInfoOf(c); ok {
+			return info.severity
+		}
+	}
+
+	return SeverityError
+}
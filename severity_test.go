@@ -0,0 +1,34 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverityOf(t *testing.T) {
+	assert.Equal(t, failure.SeverityError, failure.SeverityOf(failure.System("foo")))
+	assert.Equal(t, failure.SeverityCritical, failure.SeverityOf(failure.Panic("foo")))
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(failure.Warn("foo")))
+	assert.Equal(t, failure.SeverityDebug, failure.SeverityOf(failure.Ignore("foo")))
+	assert.Equal(t, failure.SeverityError, failure.SeverityOf(errors.New("foo")))
+}
+
+func TestWithSeverity(t *testing.T) {
+	err := failure.NotFound("user 1")
+	sev := failure.WithSeverity(err, failure.SeverityCritical)
+
+	assert.Equal(t, failure.SeverityCritical, failure.SeverityOf(sev))
+	assert.Equal(t, failure.SeverityCritical, failure.SeverityOf(failure.Wrap(sev, "load user")))
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(err))
+
+	assert.True(t, failure.IsNotFound(sev))
+	assert.Equal(t, err.Error(), sev.Error())
+	assert.True(t, errors.Is(sev, err))
+
+	assert.Nil(t, failure.WithSeverity(nil, failure.SeverityError))
+	assert.Equal(t, "critical", failure.SeverityCritical.String())
+	assert.Equal(t, "unknown", failure.Severity(42).String())
+}
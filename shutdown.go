@@ -0,0 +1,91 @@
+package failure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+)
+
+var shutdown = struct {
+	sync.RWMutex
+	active bool
+	extra  []Matcher
+}{}
+
+// BeginShutdown switches to shutdown mode, where the failures expected while
+// tearing down are downgraded to at most SeverityWarning by SeverityOf and
+// LevelFor, so they no longer reach Report or page anyone. Expected failures
+// are Canceled, Shutdown and Unavailable failures, the latter from
+// dependencies draining too, context cancellation, closed network
+// connections and closed servers, plus anything matched by extra.
+func BeginShutdown(extra ...Matcher) {
+	shutdown.Lock()
+	defer shutdown.Unlock()
+	shutdown.active = true
+	shutdown.extra = append([]Matcher(nil), extra...)
+}
+
+// EndShutdown leaves shutdown mode, it is mostly useful in tests
+func EndShutdown() {
+	shutdown.Lock()
+	defer shutdown.Unlock()
+	shutdown.active = false
+	shutdown.extra = nil
+}
+
+// ShuttingDown reports whether BeginShutdown was called
+func ShuttingDown() bool {
+	shutdown.RLock()
+	defer shutdown.RUnlock()
+	return shutdown.active
+}
+
+// expectedTeardown reports whether e is expected while shutting down. A
+// Multi is never expected as a whole, one of its members may be a real
+// failure.
+func expectedTeardown(e error) bool {
+	shutdown.RLock()
+	active, extra := shutdown.active, shutdown.extra
+	shutdown.RUnlock()
+
+	if !active || e == nil {
+		return false
+	}
+	if _, ok := e.(*Multi); ok {
+		return false
+	}
+
+	switch {
+	case errors.Is(e, KindCanceled), errors.Is(e, KindShutdown), errors.Is(e, KindUnavailable),
+		errors.Is(e, context.Canceled), errors.Is(e, net.ErrClosed),
+		errors.Is(e, http.ErrServerClosed):
+		return true
+	}
+
+	for _, m := range extra {
+		if m(e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// downgradeSeverity caps s during shutdown, see BeginShutdown
+func downgradeSeverity(e error, s Severity) Severity {
+	if s > SeverityWarning && expectedTeardown(e) {
+		return SeverityWarning
+	}
+	return s
+}
+
+// downgradeLevel caps l during shutdown, see BeginShutdown
+func downgradeLevel(e error, l slog.Level) slog.Level {
+	if l > slog.LevelWarn && expectedTeardown(e) {
+		return slog.LevelWarn
+	}
+	return l
+}
@@ -0,0 +1,47 @@
+package failure_test
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeginShutdown(t *testing.T) {
+	draining := failure.System("upstream draining")
+	real := failure.System("db down")
+
+	assert.False(t, failure.ShuttingDown())
+	assert.Equal(t, failure.SeverityError, failure.SeverityOf(failure.Wrap(net.ErrClosed, "read")))
+
+	failure.BeginShutdown(failure.MessageMatcher("*draining*"))
+	defer failure.EndShutdown()
+	assert.True(t, failure.ShuttingDown())
+
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(failure.Wrap(net.ErrClosed, "read")))
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(failure.Wrap(context.Canceled, "poll")))
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(draining))
+	assert.Equal(t, failure.SeverityWarning, failure.SeverityOf(failure.Unavailable("billing")))
+	assert.Equal(t, failure.SeverityError, failure.SeverityOf(real))
+	assert.Equal(t, slog.LevelWarn, failure.LevelFor(draining))
+
+	// already below the cap, nothing changes
+	assert.Equal(t, failure.SeverityInfo, failure.SeverityOf(failure.Canceled("request")))
+
+	// a Multi may hide a real failure
+	multi := failure.Append(nil, failure.Wrap(context.Canceled, "a"), real)
+	assert.Equal(t, failure.SeverityError, failure.SeverityOf(multi))
+
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+	failure.Report(context.Background(), draining)
+	failure.Report(context.Background(), real)
+	assert.Equal(t, []error{real}, rec.reported())
+
+	failure.SetCategoryLevels(map[failure.Category]slog.Level{failure.KindSystem: slog.LevelError})
+	defer failure.SetCategoryLevels(nil)
+	assert.Equal(t, slog.LevelWarn, failure.LevelFor(draining))
+}
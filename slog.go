@@ -0,0 +1,190 @@
+package failure
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// DefaultLogAttrPrefix is the prefix of the keys returned by LogAttrs
+const DefaultLogAttrPrefix = "error"
+
+var logAttrPrefix = struct {
+	sync.RWMutex
+	value string
+}{value: DefaultLogAttrPrefix}
+
+// SetLogAttrPrefix changes the prefix of the keys returned by LogAttrs, an
+// empty prefix leaves the keys bare.
+func SetLogAttrPrefix(prefix string) {
+	logAttrPrefix.Lock()
+	defer logAttrPrefix.Unlock()
+	logAttrPrefix.value = prefix
+}
+
+// LogAttrs returns the attributes describing e so they can be spliced into
+// a log record next to the caller's own fields:
+//
+//	logger.LogAttrs(ctx, failure.LevelFor(err), "charge failed",
+//		append(failure.LogAttrs(err), slog.String("order_id", id))...)
+//
+// The message is always present, category, log level, see LevelFor,
+// fingerprint, message chain, invalid fields, details and stack only when
+// they apply. Keys are prefixed, "error.message" by default, to avoid
+// colliding with the caller's fields.
+func LogAttrs(e error) []slog.Attr {
+	logAttrPrefix.RLock()
+	prefix := logAttrPrefix.value
+	logAttrPrefix.RUnlock()
+
+	return logAttrs(e, prefix)
+}
+
+// LogValue returns the attributes of LogAttrs as a group value, without
+// the prefix, for logging e under a key of its own:
+//
+//	logger.Error("charge failed", "err", failure.LogValue(err))
+func LogValue(e error) slog.Value {
+	return slog.GroupValue(logAttrs(e, "")...)
+}
+
+// ReplaceAttr is a slog.HandlerOptions.ReplaceAttr function logging every
+// error attribute as the group of LogValue, so a plain
+// logger.Error("charge failed", "err", err) produces structured output:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+//		ReplaceAttr: failure.ReplaceAttr,
+//	}))
+func ReplaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindAny {
+		return a
+	}
+	if e, ok := a.Value.Any().(error); ok && e != nil {
+		a.Value = LogValue(e)
+	}
+
+	return a
+}
+
+func logAttrs(e error, prefix string) []slog.Attr {
+	if e == nil {
+		return nil
+	}
+
+	key := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	attrs := []slog.Attr{slog.String(key("message"), e.Error())}
+
+	if c, ok := Kind(e); ok {
+		attrs = append(attrs,
+			slog.String(key("category"), c.String()),
+			slog.String(key("level"), LevelFor(e).String()),
+			slog.Bool(key("retryable"), IsRetryable(e)),
+		)
+	}
+
+	attrs = append(attrs, slog.String(key("fingerprint"), Fingerprint(e)))
+
+	if chain := MessageChain(e); len(chain) > 1 {
+		attrs = append(attrs, slog.Any(key("chain"), chain))
+	}
+
+	if fields := invalidFields(e); len(fields) > 0 {
+		attrs = append(attrs, slog.Any(key("fields"), fields))
+	}
+
+	if details := Details(e); len(details) > 0 {
+		names := make([]string, 0, len(details))
+		for k := range details {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		group := make([]interface{}, 0, len(names))
+		for _, k := range names {
+			group = append(group, slog.Any(k, details[k]))
+		}
+		attrs = append(attrs, slog.Group(key("details"), group...))
+	}
+
+	if frames, ok := StackTrace(e); ok {
+		attrs = append(attrs, slog.String(key("stack"), FormatStack(frames)))
+	}
+
+	return attrs
+}
+
+// invalidFields returns the failed fields of the RestAPI or Catalog in the
+// chain of e, Catalog fields are named "group.field".
+func invalidFields(e error) map[string]string {
+	if fields, ok := GetInvalidFields(e); ok && len(fields) > 0 {
+		return fields
+	}
+
+	var c *Catalog
+	if !errors.As(e, &c) {
+		return nil
+	}
+
+	out := map[string]string{}
+	for group, fields := range c.AllFailures() {
+		for name, msg := range fields {
+			out[fieldPath(group, name)] = msg
+		}
+	}
+
+	return out
+}
+
+// MultiLogLimit is the number of failures a Multi includes when logged
+// through slog, the rest are only counted.
+const MultiLogLimit = 10
+
+// LogValue implements slog.LogValuer so a Multi logs as a group holding the
+// number of failures, their tally by category and the messages of the first
+// MultiLogLimit of them, instead of one multiline string.
+func (e *Multi) LogValue() slog.Value {
+	if e == nil {
+		return slog.GroupValue(slog.Int("count", 0))
+	}
+
+	flat := Flatten(e).(*Multi).Failures
+
+	summary := e.Summary()
+	names := make([]string, 0, len(summary))
+	for name := range summary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tally := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		tally = append(tally, slog.Int(name, summary[name]))
+	}
+
+	shown := flat
+	if len(shown) > MultiLogLimit {
+		shown = shown[:MultiLogLimit]
+	}
+	messages := make([]string, len(shown))
+	for i, x := range shown {
+		messages[i] = x.Error()
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("count", len(flat)),
+		slog.Attr{Key: "categories", Value: slog.GroupValue(tally...)},
+		slog.Any("failures", messages),
+	}
+	if omitted := len(flat) - len(shown); omitted > 0 {
+		attrs = append(attrs, slog.Int("omitted", omitted))
+	}
+
+	return slog.GroupValue(attrs...)
+}
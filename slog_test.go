@@ -0,0 +1,106 @@
+package failure_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func attrMap(attrs []slog.Attr) map[string]slog.Value {
+	m := map[string]slog.Value{}
+	for _, a := range attrs {
+		m[a.Key] = a.Value
+	}
+	return m
+}
+
+func TestLogAttrs(t *testing.T) {
+	err := failure.WithDetail(failure.NotFound("user 1"), "user_id", 1)
+	m := attrMap(failure.LogAttrs(err))
+
+	assert.Equal(t, err.Error(), m["error.message"].String())
+	assert.Equal(t, "not_found", m["error.category"].String())
+	assert.Equal(t, "WARN", m["error.level"].String())
+	assert.False(t, m["error.retryable"].Bool())
+	assert.Equal(t, failure.Fingerprint(err), m["error.fingerprint"].String())
+
+	details := m["error.details"].Group()
+	require.Len(t, details, 1)
+	assert.Equal(t, "user_id", details[0].Key)
+	assert.NotContains(t, m, "error.stack")
+
+	m = attrMap(failure.LogAttrs(failure.WithStack(errors.New("foo"))))
+	assert.NotContains(t, m, "error.category")
+	assert.Contains(t, m, "error.stack")
+
+	assert.Nil(t, failure.LogAttrs(nil))
+}
+
+func TestLogAttrs_LevelOverride(t *testing.T) {
+	defer failure.SetCategoryLevels(nil)
+
+	failure.SetCategoryLevels(map[failure.Category]slog.Level{failure.KindNotFound: slog.LevelDebug})
+	m := attrMap(failure.LogAttrs(failure.NotFound("user 1")))
+	assert.Equal(t, "DEBUG", m["error.level"].String())
+}
+
+func TestSetLogAttrPrefix(t *testing.T) {
+	defer failure.SetLogAttrPrefix(failure.DefaultLogAttrPrefix)
+
+	failure.SetLogAttrPrefix("failure")
+	m := attrMap(failure.LogAttrs(failure.System("foo")))
+	assert.Contains(t, m, "failure.category")
+
+	failure.SetLogAttrPrefix("")
+	m = attrMap(failure.LogAttrs(failure.System("foo")))
+	assert.Contains(t, m, "category")
+}
+
+func TestMulti_LogValue(t *testing.T) {
+	var multi *failure.Multi
+	for i := 0; i < failure.MultiLogLimit+2; i++ {
+		multi = failure.Append(multi, failure.Timeout("call %d", i))
+	}
+	multi = failure.Append(multi, errors.New("foo"))
+
+	var v slog.LogValuer = multi
+	m := attrMap(v.LogValue().Group())
+
+	assert.Equal(t, int64(failure.MultiLogLimit+3), m["count"].Int64())
+	assert.Equal(t, int64(3), m["omitted"].Int64())
+
+	tally := attrMap(m["categories"].Group())
+	assert.Equal(t, int64(failure.MultiLogLimit+2), tally["timeout"].Int64())
+	assert.Equal(t, int64(1), tally[failure.Uncategorized].Int64())
+
+	messages, ok := m["failures"].Any().([]string)
+	require.True(t, ok)
+	require.Len(t, messages, failure.MultiLogLimit)
+	assert.Contains(t, messages[0], "call 0")
+}
+
+func TestLogValue(t *testing.T) {
+	err := failure.Wrap(failure.InvalidFields(map[string]string{"name": "is required"}, "invalid user"), "create")
+	m := attrMap(failure.LogValue(err).Group())
+
+	assert.Equal(t, err.Error(), m["message"].String())
+	assert.Equal(t, []string{"create", "invalid user", failure.InvalidAPIFieldsMsg}, m["chain"].Any())
+	assert.Equal(t, map[string]string{"name": "is required"}, m["fields"].Any())
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: failure.ReplaceAttr}))
+	logger.Error("op failed", "err", failure.NotFound("user 7"), "id", 7)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	logged, ok := record["err"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "not_found", logged["category"])
+	assert.Equal(t, float64(7), record["id"])
+}
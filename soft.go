@@ -0,0 +1,35 @@
+package failure
+
+import "context"
+
+type softKey struct{}
+
+// MarkSoft flags e as a soft failure: the request can go on in a degraded
+// way, serving a stale cache entry or skipping an enrichment, instead of
+// aborting. Failures are hard unless marked.
+func MarkSoft(e error) error {
+	return annotate(e, softKey{}, true)
+}
+
+// IsSoft reports whether e was marked with MarkSoft
+func IsSoft(e error) bool {
+	v, ok := lookup(e, softKey{})
+	return ok && v.(bool)
+}
+
+// Degrade is the single place where soft failures are absorbed: a soft e is
+// sent to Report and nil is returned so the caller carries on degraded, a
+// hard e is returned as is.
+//
+//	profile, err := enrich(ctx, user)
+//	if err = failure.Degrade(ctx, err); err != nil {
+//		return err
+//	}
+func Degrade(ctx context.Context, e error) error {
+	if e == nil || !IsSoft(e) {
+		return e
+	}
+
+	Report(ctx, e)
+	return nil
+}
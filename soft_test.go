@@ -0,0 +1,46 @@
+package failure_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkSoft(t *testing.T) {
+	err := failure.System("recommendations unavailable")
+	assert.False(t, failure.IsSoft(err))
+	assert.True(t, failure.IsSoft(failure.MarkSoft(err)))
+	assert.True(t, failure.IsSoft(failure.Wrap(failure.MarkSoft(err), "enrich")))
+	assert.False(t, failure.IsSoft(errors.New("foo")))
+}
+
+func TestDegrade(t *testing.T) {
+	rec := &recorder{}
+	defer failure.RegisterReporter(rec)()
+
+	ctx := context.Background()
+	hard := failure.System("db down")
+	assert.Equal(t, hard, failure.Degrade(ctx, hard))
+	assert.NoError(t, failure.Degrade(ctx, nil))
+
+	soft := failure.MarkSoft(failure.System("cache down"))
+	assert.NoError(t, failure.Degrade(ctx, soft))
+
+	reported := rec.reported()
+	require.Len(t, reported, 1)
+	assert.Equal(t, soft, reported[0])
+}
+
+func TestRetry_Soft(t *testing.T) {
+	calls := 0
+	err := failure.Retry(context.Background(), failure.RetryPolicy{Attempts: 3, Backoff: noWait}, func(context.Context) error {
+		calls++
+		return failure.MarkSoft(failure.Timeout("enrichment"))
+	})
+	assert.True(t, failure.IsSoft(err))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,157 @@
+// Package sqlfail classifies the errors of database/sql and its drivers.
+//
+// The package does not import any driver. PostgreSQL errors are recognized
+// by their SQLState method, which the errors of pgx (*pgconn.PgError) and
+// lib/pq (*pq.Error) have, and MySQL errors by the Number of the
+// *mysql.MySQLError of github.com/go-sql-driver/mysql.
+package sqlfail
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"reflect"
+
+	"github.com/rsb/failure"
+)
+
+// postgres maps SQLSTATE codes to categories, see postgresClasses for the
+// codes matched by class.
+var postgres = map[string]failure.Category{
+	"23505": failure.KindAlreadyExists,     // unique_violation
+	"40001": failure.KindConflict,          // serialization_failure
+	"40P01": failure.KindConflict,          // deadlock_detected
+	"55P03": failure.KindConflict,          // lock_not_available
+	"53300": failure.KindResourceExhausted, // too_many_connections
+	"57014": failure.KindTimeout,           // query_canceled, statement_timeout
+	"57P01": failure.KindUnavailable,       // admin_shutdown
+	"57P02": failure.KindUnavailable,       // crash_shutdown
+	"57P03": failure.KindUnavailable,       // cannot_connect_now
+}
+
+// postgresClasses maps the first two characters of SQLSTATE codes
+var postgresClasses = map[string]failure.Category{
+	"08": failure.KindUnavailable, // connection_exception
+	"23": failure.KindValidation,  // integrity_constraint_violation
+}
+
+// mysql maps MySQL server error numbers to categories
+var mysql = map[uint16]failure.Category{
+	1062: failure.KindAlreadyExists,     // ER_DUP_ENTRY
+	1586: failure.KindAlreadyExists,     // ER_DUP_ENTRY_WITH_KEY_NAME
+	1213: failure.KindConflict,          // ER_LOCK_DEADLOCK
+	1205: failure.KindTimeout,           // ER_LOCK_WAIT_TIMEOUT
+	3024: failure.KindTimeout,           // ER_QUERY_TIMEOUT
+	1040: failure.KindResourceExhausted, // ER_CON_COUNT_ERROR
+	1048: failure.KindValidation,        // ER_BAD_NULL_ERROR
+	1451: failure.KindValidation,        // ER_ROW_IS_REFERENCED_2
+	1452: failure.KindValidation,        // ER_NO_REFERENCED_ROW_2
+}
+
+// From wraps err with the message and classifies it:
+//
+//   - sql.ErrNoRows is NotFound
+//   - unique violations, PostgreSQL 23505 and MySQL 1062, are AlreadyExists
+//   - serialization failures and deadlocks are Conflict
+//   - other integrity violations, foreign keys and not null, are Validation
+//   - broken connections, refused dials and servers shutting down are
+//     Unavailable
+//   - timeouts and cancellations are Timeout and Canceled
+//
+// The original error stays reachable with errors.Is and errors.As, errors
+// that are none of the above are only wrapped. nil is returned when err is
+// nil.
+//
+//	err := db.QueryRowContext(ctx, q, id).Scan(&u.Name)
+//	if err != nil {
+//		return sqlfail.From(err, "select user %d", id)
+//	}
+func From(err error, format string, a ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	if c, ok := Category(err); ok {
+		return failure.To(c, err, format, a...)
+	}
+
+	return failure.Wrap(err, format, a...)
+}
+
+// Category returns the category From gives err
+func Category(err error) (failure.Category, bool) {
+	switch {
+	case err == nil:
+		return "", false
+	case errors.Is(err, sql.ErrNoRows):
+		return failure.KindNotFound, true
+	case errors.Is(err, context.DeadlineExceeded):
+		return failure.KindTimeout, true
+	case errors.Is(err, context.Canceled):
+		return failure.KindCanceled, true
+	}
+
+	var pg interface{ SQLState() string }
+	if errors.As(err, &pg) {
+		code := pg.SQLState()
+		if c, ok := postgres[code]; ok {
+			return c, true
+		}
+		if len(code) == 5 {
+			if c, ok := postgresClasses[code[:2]]; ok {
+				return c, true
+			}
+		}
+		return "", false
+	}
+
+	if n, ok := mysqlNumber(err); ok {
+		c, ok := mysql[n]
+		return c, ok
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return failure.KindUnavailable, true
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) {
+		if ne.Timeout() {
+			return failure.KindTimeout, true
+		}
+		return failure.KindUnavailable, true
+	}
+
+	return "", false
+}
+
+// mysqlNumber returns the Number of a *mysql.MySQLError in the chain of
+// err, read by reflection so the driver isn't a dependency.
+func mysqlNumber(err error) (uint16, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Struct &&
+		v.Elem().Type().Name() == "MySQLError" {
+		if f := v.Elem().FieldByName("Number"); f.IsValid() && f.Kind() == reflect.Uint16 {
+			return uint16(f.Uint()), true
+		}
+	}
+
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			if n, ok := mysqlNumber(e); ok {
+				return n, true
+			}
+		}
+	case interface{ Unwrap() error }:
+		return mysqlNumber(x.Unwrap())
+	}
+
+	return 0, false
+}
@@ -0,0 +1,85 @@
+package sqlfail_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/rsb/failure/sqlfail"
+	"github.com/stretchr/testify/assert"
+)
+
+// pgError mimics *pgconn.PgError
+type pgError struct {
+	Code string
+}
+
+func (e *pgError) Error() string    { return "pg: " + e.Code }
+func (e *pgError) SQLState() string { return e.Code }
+
+// MySQLError mimics *mysql.MySQLError
+type MySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *MySQLError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind failure.Category
+	}{
+		{err: sql.ErrNoRows, kind: failure.KindNotFound},
+		{err: fmt.Errorf("scan: %w", sql.ErrNoRows), kind: failure.KindNotFound},
+		{err: &pgError{Code: "23505"}, kind: failure.KindAlreadyExists},
+		{err: &pgError{Code: "40001"}, kind: failure.KindConflict},
+		{err: &pgError{Code: "23503"}, kind: failure.KindValidation},
+		{err: &pgError{Code: "08006"}, kind: failure.KindUnavailable},
+		{err: &pgError{Code: "57014"}, kind: failure.KindTimeout},
+		{err: &MySQLError{Number: 1062, Message: "Duplicate entry"}, kind: failure.KindAlreadyExists},
+		{err: &MySQLError{Number: 1213}, kind: failure.KindConflict},
+		{err: driver.ErrBadConn, kind: failure.KindUnavailable},
+		{err: sql.ErrConnDone, kind: failure.KindUnavailable},
+		{err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, kind: failure.KindUnavailable},
+		{err: &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, kind: failure.KindTimeout},
+		{err: context.Canceled, kind: failure.KindCanceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			err := sqlfail.From(tt.err, "insert user %d", 7)
+			kind, ok := failure.Kind(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.kind, kind)
+			assert.True(t, errors.Is(err, tt.err))
+			assert.Contains(t, err.Error(), "insert user 7: ")
+		})
+	}
+
+	// already classified errors keep their driver error visible
+	kind, ok := sqlfail.Category(failure.To(failure.KindSystem, &MySQLError{Number: 1062}, "insert"))
+	assert.True(t, ok)
+	assert.Equal(t, failure.KindAlreadyExists, kind)
+}
+
+func TestFrom_Unknown(t *testing.T) {
+	assert.NoError(t, sqlfail.From(nil, "insert"))
+
+	raw := errors.New("syntax error")
+	err := sqlfail.From(raw, "insert")
+	_, ok := failure.Kind(err)
+	assert.False(t, ok)
+	assert.True(t, errors.Is(err, raw))
+
+	_, ok = sqlfail.Category(&pgError{Code: "42601"})
+	assert.False(t, ok)
+	_, ok = sqlfail.Category(&MySQLError{Number: 1064})
+	assert.False(t, ok)
+}
@@ -0,0 +1,113 @@
+package failure
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// maxStackDepth bounds the number of frames captured for a stack trace
+const maxStackDepth = 64
+
+// Frame is a single call in a stack trace
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// String formats the frame the way the Go runtime prints panics
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+type stackKey struct{}
+
+// WithStack returns e annotated with the stack trace of its caller
+func WithStack(e error) error {
+	if e == nil {
+		return nil
+	}
+
+	return annotate(e, stackKey{}, callers(3))
+}
+
+var stackTraces atomic.Bool
+
+// EnableStackTraces makes every constructor of this package capture the
+// stack trace of its caller, System, NotFound, Wrap and the rest, so the
+// call site of a failure is known when debugging an incident. It is off by
+// default since capturing a stack has a cost, WithStack captures one for a
+// single error.
+func EnableStackTraces(on bool) {
+	stackTraces.Store(on)
+}
+
+// captureStack annotates e with the stack trace of the caller of the
+// package function that built it, when EnableStackTraces is on and e
+// doesn't carry a stack trace yet.
+func captureStack(e error) error {
+	if !stackTraces.Load() {
+		return e
+	}
+	if _, ok := lookup(e, stackKey{}); ok {
+		return e
+	}
+
+	return annotate(e, stackKey{}, trimInternal(callers(3)))
+}
+
+// internalPrefix is the function name prefix of this package
+const internalPrefix = "github.com/rsb/failure."
+
+// trimInternal drops the leading frames inside this package, so the trace
+// starts at the code that asked for the failure.
+func trimInternal(frames []Frame) []Frame {
+	for i, f := range frames {
+		if !strings.HasPrefix(f.Function, internalPrefix) {
+			return frames[i:]
+		}
+	}
+
+	return frames
+}
+
+// StackTrace returns the outermost stack trace captured for e
+func StackTrace(e error) ([]Frame, bool) {
+	v, ok := lookup(e, stackKey{})
+	if !ok {
+		return nil, false
+	}
+
+	return v.([]Frame), true
+}
+
+// FormatStack renders frames one call per entry, innermost first
+func FormatStack(frames []Frame) string {
+	lines := make([]string, len(frames))
+	for i, f := range frames {
+		lines[i] = f.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// callers captures the current stack, skip has the same meaning as in
+// runtime.Callers.
+func callers(skip int) []Frame {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	it := runtime.CallersFrames(pcs[:n])
+
+	var frames []Frame
+	for {
+		f, more := it.Next()
+		frames = append(frames, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+
+	return frames
+}
@@ -0,0 +1,51 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStack(t *testing.T) {
+	err := failure.WithStack(failure.System("foo"))
+	assert.True(t, failure.IsSystem(err))
+
+	frames, ok := failure.StackTrace(failure.Wrap(err, "bar"))
+	require.True(t, ok)
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/rsb/failure_test.TestWithStack", frames[0].Function)
+	assert.Contains(t, frames[0].File, "stack_test.go")
+
+	out := failure.FormatStack(frames[:1])
+	assert.Contains(t, out, "failure_test.TestWithStack\n\t")
+	assert.Contains(t, out, "stack_test.go:")
+
+	_, ok = failure.StackTrace(errors.New("foo"))
+	assert.False(t, ok)
+	assert.Nil(t, failure.WithStack(nil))
+}
+
+func TestEnableStackTraces(t *testing.T) {
+	_, ok := failure.StackTrace(failure.NotFound("user"))
+	assert.False(t, ok)
+
+	failure.EnableStackTraces(true)
+	defer failure.EnableStackTraces(false)
+
+	for _, err := range []error{
+		failure.NotFound("user"),
+		failure.ToSystem(errors.New("db"), "load"),
+		failure.BadRequest("missing id"),
+	} {
+		frames, ok := failure.StackTrace(err)
+		require.True(t, ok, err.Error())
+		assert.Equal(t, "github.com/rsb/failure_test.TestEnableStackTraces", frames[0].Function)
+	}
+
+	err := failure.NotFound("user")
+	assert.True(t, failure.IsNotFound(err))
+	assert.Equal(t, "user: "+failure.NotFoundMsg, err.Error())
+}
@@ -0,0 +1,83 @@
+package failure
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// statusClientClosedRequest is the non standard status used when the
+// client went away before the response was written.
+const statusClientClosedRequest = 499
+
+// TaxonomyFormat is the encoding used by ExportTaxonomy
+type TaxonomyFormat string
+
+const (
+	TaxonomyJSON TaxonomyFormat = "json"
+	TaxonomyYAML TaxonomyFormat = "yaml"
+)
+
+// CategoryDescription documents a category and how it is handled
+type CategoryDescription struct {
+	Name       string `json:"name" yaml:"name"`
+	Message    string `json:"message" yaml:"message"`
+	Severity   string `json:"severity" yaml:"severity"`
+	Retryable  bool   `json:"retryable" yaml:"retryable"`
+	HTTPStatus int    `json:"http_status" yaml:"http_status"`
+	GRPCCode   string `json:"grpc_code" yaml:"grpc_code"`
+}
+
+// Taxonomy describes every category, sorted by name
+func Taxonomy() []CategoryDescription {
+	all := allCategories()
+	list := make([]CategoryDescription, 0, len(all))
+	for c, info := range all {
+		list = append(list, CategoryDescription{
+			Name:       c.String(),
+			Message:    c.Error(),
+			Severity:   info.severity.String(),
+			Retryable:  info.retryable,
+			HTTPStatus: info.status,
+			GRPCCode:   info.grpc.String(),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// ExportTaxonomy writes the Taxonomy to w, feeding API documentation,
+// client SDK generation and runbooks.
+func ExportTaxonomy(w io.Writer, format TaxonomyFormat) error {
+	doc := struct {
+		Categories []CategoryDescription `json:"categories" yaml:"categories"`
+	}{Taxonomy()}
+
+	switch format {
+	case TaxonomyJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			return Wrap(err, "json encode failed")
+		}
+	case TaxonomyYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return Wrap(err, "yaml encode failed")
+		}
+		if err := enc.Close(); err != nil {
+			return Wrap(err, "yaml encoder close failed")
+		}
+	default:
+		return InvalidParam("unknown taxonomy format (%s)", format)
+	}
+
+	return nil
+}
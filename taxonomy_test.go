@@ -0,0 +1,58 @@
+package failure_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTaxonomy(t *testing.T) {
+	list := failure.Taxonomy()
+	require.NotEmpty(t, list)
+
+	byName := map[string]failure.CategoryDescription{}
+	for i, d := range list {
+		if i > 0 {
+			assert.Less(t, list[i-1].Name, d.Name)
+		}
+		byName[d.Name] = d
+	}
+
+	assert.Equal(t, failure.CategoryDescription{
+		Name:       "not_found",
+		Message:    failure.NotFoundMsg,
+		Severity:   "warning",
+		HTTPStatus: 404,
+		GRPCCode:   "NotFound",
+	}, byName["not_found"])
+
+	timeout := byName["timeout"]
+	assert.True(t, timeout.Retryable)
+	assert.Equal(t, 504, timeout.HTTPStatus)
+	assert.Equal(t, "DeadlineExceeded", timeout.GRPCCode)
+}
+
+func TestExportTaxonomy(t *testing.T) {
+	var doc struct {
+		Categories []failure.CategoryDescription `json:"categories" yaml:"categories"`
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, failure.ExportTaxonomy(&buf, failure.TaxonomyJSON))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, failure.Taxonomy(), doc.Categories)
+
+	buf.Reset()
+	doc.Categories = nil
+	require.NoError(t, failure.ExportTaxonomy(&buf, failure.TaxonomyYAML))
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, failure.Taxonomy(), doc.Categories)
+
+	err := failure.ExportTaxonomy(&buf, "toml")
+	assert.True(t, failure.IsInvalidParam(err))
+}
@@ -0,0 +1,62 @@
+package failure
+
+// netTimeout marks a Timeout failure as a net.Error, so code checking
+// errors.As(err, &netErr) && netErr.Timeout(), such as HTTP clients,
+// database drivers and retry libraries, recognizes it. It only ever wraps
+// a Timeout: a wrapper answering false would hide a real network timeout
+// further down the chain from errors.As.
+type netTimeout struct {
+	err error
+}
+
+func (t *netTimeout) Error() string {
+	return t.err.Error()
+}
+
+func (t *netTimeout) Unwrap() error {
+	return t.err
+}
+
+// Timeout reports true, as net.Error requires
+func (t *netTimeout) Timeout() bool {
+	return true
+}
+
+// Temporary reports true, a timeout may succeed when tried again. net.Error
+// deprecated it but it is still part of the interface.
+func (t *netTimeout) Temporary() bool {
+	return true
+}
+
+// markTimeout wraps e as a net.Error when inner, the error it was built
+// from, classifies it as a Timeout.
+func markTimeout(e, inner error) error {
+	switch x := inner.(type) {
+	case Category:
+		if x != KindTimeout {
+			return e
+		}
+	case *classified:
+		if x.kind != KindTimeout {
+			return e
+		}
+	default:
+		return e
+	}
+
+	return &netTimeout{err: e}
+}
+
+// isTimeout reports whether something in the chain of e reports a timeout,
+// net.Error, os.ErrDeadlineExceeded and context.DeadlineExceeded all do. A
+// layer whose Timeout returns false doesn't hide a deeper one.
+func isTimeout(e error) bool {
+	var found bool
+	Walk(e, func(x error) bool {
+		t, ok := x.(interface{ Timeout() bool })
+		found = ok && t.Timeout()
+		return !found
+	})
+
+	return found
+}
@@ -0,0 +1,68 @@
+package failure_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout_NetError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	timeouts := []error{
+		failure.Timeout("db"),
+		failure.ToTimeout(errors.New("slow"), "db"),
+		failure.New(failure.KindTimeout, "db"),
+		failure.To(failure.KindTimeout, errors.New("slow"), "db"),
+		failure.WrapContext(ctx, errors.New("slow"), "db"),
+		failure.FromContext(ctx),
+		failure.WithDetail(failure.Wrap(failure.Timeout("db"), "query"), "table", "users"),
+	}
+	for _, err := range timeouts {
+		var ne net.Error
+		require.True(t, errors.As(err, &ne), err.Error())
+		assert.True(t, ne.Timeout())
+	}
+
+	_, ok := failure.Timeout("db").(net.Error)
+	assert.True(t, ok)
+
+	// other failures don't hide a network timeout they wrap
+	var ne net.Error
+	netErr := &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}
+	assert.True(t, errors.As(failure.To(failure.KindSystem, netErr, "read"), &ne))
+	assert.True(t, ne.Timeout())
+	assert.False(t, errors.As(failure.System("db"), &ne))
+
+	assert.Equal(t, []string{"db", failure.TimeoutMsg}, failure.MessageChain(failure.Timeout("db")))
+}
+
+func TestIsTimeout_Standard(t *testing.T) {
+	assert.True(t, failure.IsTimeout(os.ErrDeadlineExceeded))
+	assert.True(t, failure.IsTimeout(failure.Wrap(context.DeadlineExceeded, "query")))
+	assert.True(t, failure.IsTimeout(&net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}))
+	assert.False(t, failure.IsTimeout(&net.OpError{Op: "dial", Err: errors.New("refused")}))
+	assert.False(t, failure.IsTimeout(context.Canceled))
+}
+
+// notTimeout wraps an error and answers false to Timeout
+type notTimeout struct{ err error }
+
+func (n *notTimeout) Error() string   { return "not a timeout: " + n.err.Error() }
+func (n *notTimeout) Unwrap() error   { return n.err }
+func (n *notTimeout) Timeout() bool   { return false }
+func (n *notTimeout) Temporary() bool { return false }
+
+func TestIsTimeout_Deeper(t *testing.T) {
+	deep := &notTimeout{err: os.ErrDeadlineExceeded}
+	assert.True(t, failure.IsTimeout(failure.Wrap(deep, "read")))
+	assert.False(t, failure.IsTimeout(&notTimeout{err: errors.New("closed")}))
+}
@@ -0,0 +1,59 @@
+package failure
+
+import (
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+var maxErrorLength atomic.Int64
+
+// SetMaxErrorLength bounds the length in bytes of rendered error strings:
+// messages built by Wrap and every constructor, Multi output and RestAPI
+// messages sent to clients. Longer strings keep their beginning and end and
+// lose their middle, replaced by a marker with the number of bytes omitted,
+// so a failure embedding a huge payload can't blow up log quotas or
+// responses. Limits too small to hold the marker simply keep the beginning.
+// Zero, the default, means no limit.
+func SetMaxErrorLength(n int) {
+	if n < 0 {
+		n = 0
+	}
+	maxErrorLength.Store(int64(n))
+}
+
+// truncate applies the limit set with SetMaxErrorLength to s, it reports
+// whether s was cut.
+func truncate(s string) (string, bool) {
+	max := int(maxErrorLength.Load())
+	if max == 0 || len(s) <= max {
+		return s, false
+	}
+
+	// size the marker for the largest count it can hold so the result
+	// never exceeds max
+	room := max - len(truncationMarker(len(s)))
+	if room < 2 {
+		// too short for the marker, keep what fits of the beginning
+		cut := max
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		return s[:cut], true
+	}
+
+	head := room / 2
+	tail := len(s) - (room - head)
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+
+	return s[:head] + truncationMarker(tail-head) + s[tail:], true
+}
+
+func truncationMarker(omitted int) string {
+	return "…[" + strconv.Itoa(omitted) + " bytes omitted]…"
+}
@@ -0,0 +1,46 @@
+package failure_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMaxErrorLength(t *testing.T) {
+	failure.SetMaxErrorLength(60)
+	defer failure.SetMaxErrorLength(0)
+
+	dump := strings.Repeat("x", 500)
+	err := failure.Validation("payload %s rejected", dump)
+
+	msg := err.Error()
+	assert.LessOrEqual(t, len(msg), 60)
+	assert.True(t, strings.HasPrefix(msg, "payload xx"))
+	assert.True(t, strings.HasSuffix(msg, "failure"))
+	assert.Contains(t, msg, "bytes omitted]")
+	assert.True(t, failure.IsValidation(err))
+
+	short := failure.NotFound("user 1")
+	assert.Equal(t, "user 1: "+failure.NotFoundMsg, short.Error())
+
+	multi := failure.Append(nil, errors.New(dump), errors.New(dump))
+	assert.LessOrEqual(t, len(multi.Error()), 60)
+
+	resp := failure.NewErrorResponse(failure.BadRequest("bad body %s", dump))
+	assert.LessOrEqual(t, len(resp.Message), 60)
+
+	// multi-byte runes are never split
+	err = failure.Wrap(errors.New(strings.Repeat("é", 200)), "decode")
+	assert.True(t, strings.HasPrefix(err.Error(), "decode: é"))
+	assert.True(t, strings.HasSuffix(err.Error(), "é"))
+	assert.True(t, strings.ToValidUTF8(err.Error(), "?") == err.Error())
+
+	// limits smaller than the marker still hold
+	failure.SetMaxErrorLength(10)
+	msg = failure.Wrap(errors.New(strings.Repeat("é", 200)), "decode").Error()
+	assert.LessOrEqual(t, len(msg), 10)
+	assert.Equal(t, "decode: é", msg)
+}
@@ -0,0 +1,61 @@
+package failure
+
+// Walk visits err and everything it wraps, depth first, until fn returns
+// false: the layers added by Wrap and the ToX constructors, the members of a
+// Multi, the errors held by errors.Join and the Err of a RestAPI. Each
+// error is visited before what it wraps and the members of a Multi or a
+// joined error in order, so a redaction or metrics pass doesn't have to
+// know how every failure unwraps:
+//
+//	failure.Walk(err, func(e error) bool {
+//		if c, ok := e.(failure.Category); ok {
+//			failuresTotal.WithLabelValues(c.String()).Inc()
+//		}
+//		return true
+//	})
+//
+// It returns false when the walk was stopped.
+func Walk(err error, fn func(error) bool) bool {
+	return WalkDepth(err, func(e error, _ int) bool {
+		return fn(e)
+	})
+}
+
+// WalkDepth is Walk with the depth of every error, the number of branches
+// taken to reach it. Members of a Multi or a joined error and the Err of a
+// RestAPI are one level deeper than their parent, a wrapped error has the
+// depth of its wrapper.
+func WalkDepth(err error, fn func(e error, depth int) bool) bool {
+	return walk(err, 0, fn)
+}
+
+func walk(e error, depth int, fn func(error, int) bool) bool {
+	if e == nil {
+		return true
+	}
+
+	if !fn(e, depth) {
+		return false
+	}
+
+	switch x := e.(type) {
+	case *Multi:
+		for _, m := range x.Failures {
+			if !walk(m, depth+1, fn) {
+				return false
+			}
+		}
+	case *RestAPI:
+		return walk(x.Err, depth+1, fn)
+	case interface{ Unwrap() []error }:
+		for _, m := range x.Unwrap() {
+			if !walk(m, depth+1, fn) {
+				return false
+			}
+		}
+	case interface{ Unwrap() error }:
+		return walk(x.Unwrap(), depth, fn)
+	}
+
+	return true
+}
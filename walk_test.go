@@ -0,0 +1,42 @@
+package failure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rsb/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	a, b := errors.New("a"), errors.New("b")
+	joined := errors.Join(a, b)
+	m := failure.Multiple([]error{joined, failure.NotFound("user")})
+	err := failure.Wrap(m, "sync")
+
+	var seen []error
+	assert.True(t, failure.Walk(err, func(e error) bool {
+		seen = append(seen, e)
+		return true
+	}))
+	assert.Equal(t, []error{err, m, joined, a, b}, seen[:5])
+	assert.Equal(t, failure.KindNotFound, seen[len(seen)-1])
+
+	seen = nil
+	assert.False(t, failure.Walk(err, func(e error) bool {
+		seen = append(seen, e)
+		return e != a
+	}))
+	assert.Equal(t, []error{err, m, joined, a}, seen)
+
+	var depths []int
+	failure.WalkDepth(err, func(e error, depth int) bool {
+		if e == m || e == joined || e == a {
+			depths = append(depths, depth)
+		}
+		return true
+	})
+	assert.Equal(t, []int{0, 1, 2}, depths)
+
+	assert.True(t, failure.Walk(nil, func(error) bool { return false }))
+}